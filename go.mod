@@ -3,6 +3,8 @@ module github.com/Arten331/bot-checker
 go 1.18
 
 require (
+	github.com/Arten331/messaging v0.0.0-20230604180541-812fddc2d3cd
+	github.com/Arten331/observability v0.0.0-20230531192752-e9c77955fe63
 	github.com/CyCoreSystems/ari v4.8.4+incompatible
 	github.com/go-chi/chi/v5 v5.0.7
 	github.com/gobwas/ws v1.0.2
@@ -14,15 +16,16 @@ require (
 	github.com/stretchr/testify v1.8.0
 	github.com/valyala/fastjson v1.6.3
 	github.com/zaf/agi v0.0.0-20220109201550-cdecf9a1b285
+	go.uber.org/goleak v1.2.1
 	go.uber.org/ratelimit v0.2.0
 	go.uber.org/zap v1.24.0
+	google.golang.org/grpc v1.55.0
+	google.golang.org/protobuf v1.30.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 	nhooyr.io/websocket v1.8.7
 )
 
 require (
-	github.com/Arten331/messaging v0.0.0-20230604180541-812fddc2d3cd // indirect
-	github.com/Arten331/observability v0.0.0-20230531192752-e9c77955fe63 // indirect
 	github.com/andres-erbsen/clock v0.0.0-20160526145045-9e14626cd129 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
@@ -46,8 +49,9 @@ require (
 	github.com/prometheus/procfs v0.9.0 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
 	go.uber.org/multierr v1.6.0 // indirect
-	golang.org/x/net v0.7.0 // indirect
+	golang.org/x/net v0.8.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
-	google.golang.org/protobuf v1.30.0 // indirect
+	golang.org/x/text v0.8.0 // indirect
+	google.golang.org/genproto v0.0.0-20230306155012-7f2fa6fef1f4 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
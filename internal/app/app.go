@@ -3,11 +3,13 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"strconv"
 
 	"github.com/Arten331/bot-checker/internal/agiservice"
 	"github.com/Arten331/bot-checker/internal/app/global"
+	"github.com/Arten331/bot-checker/internal/audiosocketservice"
 	"github.com/Arten331/bot-checker/internal/botchecker"
 	"github.com/Arten331/bot-checker/internal/config"
 	"github.com/Arten331/bot-checker/internal/domain/phrase"
@@ -16,7 +18,9 @@ import (
 	checkevents "github.com/Arten331/bot-checker/internal/events/bot_checker"
 	"github.com/Arten331/bot-checker/internal/httpservice"
 	"github.com/Arten331/bot-checker/internal/httpservice/httpwriter"
+	"github.com/Arten331/bot-checker/internal/recording"
 	"github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/Arten331/bot-checker/pkg/audio"
 	"github.com/Arten331/bot-checker/pkg/kaldi"
 	kafkaClient "github.com/Arten331/messaging/kafka"
 	"github.com/Arten331/observability/metrics"
@@ -29,7 +33,11 @@ type Repositories struct {
 type Services struct {
 	httpService *httpservice.Service
 	agiService  *agiservice.Service
+	audioSocket *audiosocketservice.Service
+	kaldi       kaldi.Recognizer
+	ari         []*ari.Client
 	botChecker  *botchecker.BotChecker
+	recordings  *recording.Storage
 }
 
 type App struct {
@@ -73,10 +81,21 @@ func Init(ctx context.Context, cfg *config.AppConfig) (ac *App, err error) {
 }
 
 func (a *App) initServices(_ context.Context) error {
-	kaldiClient := kaldi.NewClient(kaldi.Options{
-		Host: a.cfg.Kaldi.Host,
-		Port: a.cfg.Kaldi.Port,
+	kaldiClient, err := kaldi.New(kaldi.Options{
+		Host:       a.cfg.Kaldi.Host,
+		Port:       a.cfg.Kaldi.Port,
+		Transport:  a.cfg.Kaldi.Transport,
+		GRPCPort:   a.cfg.Kaldi.GRPCPort,
+		SampleRate: a.cfg.Kaldi.SampleRate,
 	})
+	if err != nil {
+		return err
+	}
+
+	kaldiPort := a.cfg.Kaldi.Port
+	if a.cfg.Kaldi.Transport == kaldi.TransportGRPC {
+		kaldiPort = a.cfg.Kaldi.GRPCPort
+	}
 
 	ariCfg := a.cfg.Ari
 	ariClient := ari.New(ari.Options{
@@ -86,14 +105,105 @@ func (a *App) initServices(_ context.Context) error {
 		Password: ariCfg.Password,
 		Original: ariCfg.Original,
 		Secure:   ariCfg.Secure,
+
+		PingInterval: ariCfg.PingInterval,
+		MinBackoff:   ariCfg.MinBackoff,
+		MaxBackoff:   ariCfg.MaxBackoff,
 	})
 
+	ariClients := []*ari.Client{ariClient}
+	ariServers := make([]botchecker.AriServer, 0, len(ariCfg.Servers))
+
+	for _, server := range ariCfg.Servers {
+		client := ari.New(ari.Options{
+			Host:     server.Host,
+			Port:     server.Port,
+			User:     server.User,
+			Password: server.Password,
+			Original: ariCfg.Original,
+			Secure:   server.Secure,
+
+			PingInterval: ariCfg.PingInterval,
+			MinBackoff:   ariCfg.MinBackoff,
+			MaxBackoff:   ariCfg.MaxBackoff,
+		})
+
+		ariClients = append(ariClients, client)
+		ariServers = append(ariServers, botchecker.AriServer{
+			Name:          server.Name,
+			Client:        client,
+			MediaChannels: client,
+			UserEvents:    client,
+			Channels:      client,
+		})
+	}
+
+	var recordings *recording.Storage
+
+	if recCfg := a.cfg.Recording; recCfg.Enabled {
+		recordings, err = recording.New(recording.Options{
+			Dir:             recCfg.Dir,
+			MaxAge:          recCfg.MaxAge,
+			MaxSize:         int64(recCfg.MaxSizeMB) << 20,
+			CleanupInterval: recCfg.CleanupInterval,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	botCheckService, err := botchecker.New(&botchecker.Options{
 		MetricService:         a.metrics,
 		StopPhrasesRepository: a.repositories.stopPhrases,
 		KaldiClient:           kaldiClient,
-		AriClient:             ariClient,
-		EventPublisher:        a.events.publisher,
+		KaldiBackend:          net.JoinHostPort(a.cfg.Kaldi.Host, strconv.Itoa(kaldiPort)),
+		Admission: botchecker.AdmissionOptions{
+			MaxActive:     a.cfg.Check.MaxActive,
+			MaxPerBackend: a.cfg.Check.MaxPerBackend,
+			QueueSize:     a.cfg.Check.QueueSize,
+			QueueTimeout:  a.cfg.Check.QueueTimeout,
+		},
+		AriName:          ariCfg.Name,
+		AriClient:        ariClient,
+		AriServers:       ariServers,
+		MediaChannels:    ariClient,
+		MediaHost:        ariCfg.MediaHost,
+		MediaListen:      ariCfg.MediaListen,
+		MediaFormat:      ariCfg.MediaFormat,
+		AutoCheck:        ariCfg.AutoCheck,
+		VerdictVariables: a.cfg.Check.VerdictVariables,
+		VerdictEvent:     a.cfg.Check.VerdictEvent,
+		UserEvents:       ariClient,
+		Channels:         ariClient,
+		DialplanDecides:  a.cfg.Check.DialplanDecides,
+		SaveRecords:      recordings != nil,
+		Recordings:       recordings,
+		AudioBackend:     a.cfg.Audio.Backend,
+		SampleRate:       a.cfg.Kaldi.SampleRate,
+		RemoteChannel:    a.cfg.Audio.RemoteChannel,
+		PreRoll:          a.cfg.Audio.PreRoll,
+		Policy: botchecker.Policy{
+			MaxListen:    a.cfg.Check.MaxListen,
+			DecideWithin: a.cfg.Check.DecideWithin,
+			HangupGrace:  a.cfg.Check.HangupGrace,
+		},
+		VadEnabled:     a.cfg.Audio.VadEnabled,
+		VadSkipSilence: a.cfg.Audio.VadSkipSilence,
+		BeepEnabled:    a.cfg.Audio.BeepEnabled,
+		Beep: audio.BeepOptions{
+			Frequency:   a.cfg.Audio.BeepFrequency,
+			MinDuration: a.cfg.Audio.BeepMinDuration,
+			ThresholdDB: a.cfg.Audio.BeepThreshold,
+		},
+		SITEnabled: a.cfg.Audio.SITEnabled,
+		SIT: audio.SITOptions{
+			ThresholdDB: a.cfg.Audio.SITThreshold,
+		},
+		FaxEnabled: a.cfg.Audio.FaxEnabled,
+		Fax: audio.FaxOptions{
+			ThresholdDB: a.cfg.Audio.FaxThreshold,
+		},
+		EventPublisher: a.events.publisher,
 	})
 	if err != nil {
 		return err
@@ -105,6 +215,16 @@ func (a *App) initServices(_ context.Context) error {
 		Handler: botCheckService,
 	})
 
+	var audioSocket *audiosocketservice.Service
+
+	if a.cfg.AudioSocket.Enabled {
+		audioSocket = audiosocketservice.New(audiosocketservice.Options{
+			Host:    a.cfg.AudioSocket.Host,
+			Port:    a.cfg.AudioSocket.Port,
+			Handler: botCheckService,
+		})
+	}
+
 	rw := httpwriter.NewJSONResponseWriter()
 
 	httpService, err := httpservice.New(
@@ -122,7 +242,11 @@ func (a *App) initServices(_ context.Context) error {
 	a.services = Services{
 		httpService: httpService,
 		agiService:  agiService,
+		audioSocket: audioSocket,
+		kaldi:       kaldiClient,
+		ari:         ariClients,
 		botChecker:  botCheckService,
+		recordings:  recordings,
 	}
 
 	return err
@@ -159,14 +283,29 @@ func (a *App) initEventsServices(_ context.Context) {
 	a.events.publisher.Subscribe(eventClickStat,
 		&checkevents.BotFound{},
 		&checkevents.BotNotFounded{},
+		&checkevents.FaxFound{},
+		&checkevents.CallAbandoned{},
 	)
 }
 
 func (a *App) Run(ctx context.Context, cancelFunc context.CancelFunc) error {
 	go a.services.httpService.Run(ctx, cancelFunc)
+
+	for _, client := range a.services.ari {
+		go client.Run(ctx, cancelFunc)
+	}
+
 	go a.services.agiService.Run(ctx, cancelFunc)
 	go a.services.botChecker.Run(ctx, cancelFunc)
 
+	if a.services.audioSocket != nil {
+		go a.services.audioSocket.Run(ctx, cancelFunc)
+	}
+
+	if a.services.recordings != nil {
+		go a.services.recordings.Run(ctx, cancelFunc)
+	}
+
 	return nil
 }
 
@@ -183,5 +322,20 @@ func (a *App) Shutdown(ctx context.Context) error {
 		return err
 	}
 
+	if a.services.audioSocket != nil {
+		err = a.services.audioSocket.Shutdown(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the gRPC recognizer holds its connection
+	if closer, ok := a.services.kaldi.(io.Closer); ok {
+		err = closer.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	return err
 }
@@ -0,0 +1,127 @@
+package audiosocketservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audiosocket"
+	"github.com/Arten331/observability/logger"
+	"go.uber.org/zap"
+)
+
+// idTimeout limits the wait for the id frame opening a connection.
+const idTimeout = 5 * time.Second
+
+type AudioSocketHandler interface {
+	// AudioSocketHandler serves the connection after its id frame was read.
+	AudioSocketHandler(ctx context.Context, id string, conn net.Conn) error
+}
+
+type Service struct {
+	address  string
+	listener net.Listener
+	handler  AudioSocketHandler
+}
+
+type Options struct {
+	Host    string
+	Port    int
+	Handler AudioSocketHandler
+}
+
+func New(o Options) *Service {
+	address := net.JoinHostPort(o.Host, strconv.Itoa(o.Port))
+
+	return &Service{
+		address: address,
+		handler: o.Handler,
+	}
+}
+
+func (s *Service) Run(ctx context.Context, cancel context.CancelFunc) {
+	defer cancel()
+
+	var err error
+
+	s.listener, err = net.Listen("tcp", s.address)
+	if err != nil {
+		logger.L().Error("AudioSocket listener error", zap.Error(err))
+
+		return
+	}
+
+	logger.L().Info(fmt.Sprintf("AudioSocket service listen on %s", s.address))
+
+	go func() {
+		<-ctx.Done()
+
+		_ = s.listener.Close()
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				logger.S().Infof("Stopped AudioSocket service %s", s.listener.Addr())
+
+				return
+			}
+
+			logger.L().Error("AudioSocket accept connection error", zap.Error(err))
+
+			continue
+		}
+
+		go func() {
+			err := s.handleAudioSocket(ctx, conn)
+			if err != nil {
+				logger.L().Error("Error handle AudioSocket connect", zap.Error(err))
+			}
+		}()
+	}
+}
+
+func (s *Service) Shutdown(_ context.Context) error {
+	if s.listener == nil {
+		return nil
+	}
+
+	err := s.listener.Close()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *Service) handleAudioSocket(ctx context.Context, c net.Conn) error {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.L().Error("Session terminated:", zap.Any("error", err))
+		}
+
+		_ = c.Close()
+	}()
+
+	logger.L().Debug("handle AudioSocket", zap.String("remote", c.RemoteAddr().String()))
+
+	_ = c.SetReadDeadline(time.Now().Add(idTimeout))
+
+	frame, err := audiosocket.ReadFrame(c, nil)
+	if err != nil {
+		return err
+	}
+
+	id, err := frame.ID()
+	if err != nil {
+		return err
+	}
+
+	_ = c.SetReadDeadline(time.Time{})
+
+	return s.handler.AudioSocketHandler(ctx, id, c)
+}
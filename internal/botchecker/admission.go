@@ -0,0 +1,180 @@
+package botchecker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const defaultQueueTimeout = 2 * time.Second
+
+const (
+	RejectBusy    = "busy"
+	RejectTimeout = "queue_timeout"
+)
+
+var (
+	ErrChecksBusy   = errors.New("bot checks limit reached")
+	ErrQueueTimeout = errors.New("bot check waited too long for a slot")
+)
+
+// AdmissionOptions limit the checks running at once, zero limits are unbounded.
+type AdmissionOptions struct {
+	MaxActive     int           // checks in total
+	MaxPerBackend int           // checks on one recognizer backend
+	QueueSize     int           // checks waiting for a slot, over it they are rejected at once
+	QueueTimeout  time.Duration // of a waiting check
+}
+
+type admissionMetrics interface {
+	StoreCheckActive(backend string, delta int)
+	StoreCheckQueued(delta int)
+	StoreCheckRejected(reason string)
+}
+
+// admission hands out check slots, a slot is held by the whole check.
+type admission struct {
+	total        chan struct{}
+	perBackend   int
+	queue        chan struct{}
+	queueTimeout time.Duration
+	metrics      admissionMetrics
+
+	mu       sync.Mutex
+	backends map[string]chan struct{}
+}
+
+func newAdmission(o AdmissionOptions, m admissionMetrics) *admission {
+	a := &admission{
+		perBackend:   o.MaxPerBackend,
+		queueTimeout: o.QueueTimeout,
+		metrics:      m,
+		backends:     make(map[string]chan struct{}),
+	}
+
+	if o.MaxActive > 0 {
+		a.total = make(chan struct{}, o.MaxActive)
+	}
+
+	if o.QueueSize > 0 {
+		a.queue = make(chan struct{}, o.QueueSize)
+	}
+
+	return a
+}
+
+// acquire takes a slot for a check on backend, it waits in the queue when there is room in it.
+// The returned release gives the slot back.
+func (a *admission) acquire(ctx context.Context, backend string) (release func(), err error) {
+	slots := []chan struct{}{a.total, a.backend(backend)}
+
+	if !tryTake(slots) {
+		err = a.wait(ctx, slots)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a.metrics.StoreCheckActive(backend, 1)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			give(slots)
+			a.metrics.StoreCheckActive(backend, -1)
+		})
+	}, nil
+}
+
+func (a *admission) wait(ctx context.Context, slots []chan struct{}) error {
+	if a.queue == nil {
+		a.metrics.StoreCheckRejected(RejectBusy)
+
+		return ErrChecksBusy
+	}
+
+	select {
+	case a.queue <- struct{}{}:
+	default:
+		a.metrics.StoreCheckRejected(RejectBusy)
+
+		return ErrChecksBusy
+	}
+
+	a.metrics.StoreCheckQueued(1)
+
+	defer func() {
+		<-a.queue
+		a.metrics.StoreCheckQueued(-1)
+	}()
+
+	timer := time.NewTimer(a.queueTimeout)
+	defer timer.Stop()
+
+	for i, slot := range slots {
+		if slot == nil {
+			continue
+		}
+
+		select {
+		case slot <- struct{}{}:
+		case <-timer.C:
+			give(slots[:i])
+			a.metrics.StoreCheckRejected(RejectTimeout)
+
+			return ErrQueueTimeout
+		case <-ctx.Done():
+			give(slots[:i])
+
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (a *admission) backend(name string) chan struct{} {
+	if a.perBackend <= 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	slot, ok := a.backends[name]
+	if !ok {
+		slot = make(chan struct{}, a.perBackend)
+		a.backends[name] = slot
+	}
+
+	return slot
+}
+
+// tryTake takes a place in every limited slot or in none.
+func tryTake(slots []chan struct{}) bool {
+	for i, slot := range slots {
+		if slot == nil {
+			continue
+		}
+
+		select {
+		case slot <- struct{}{}:
+		default:
+			give(slots[:i])
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func give(slots []chan struct{}) {
+	for _, slot := range slots {
+		if slot != nil {
+			<-slot
+		}
+	}
+}
@@ -0,0 +1,141 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type admissionCounter struct {
+	mu       sync.Mutex
+	active   map[string]int
+	queued   int
+	rejected map[string]int
+}
+
+func newAdmissionCounter() *admissionCounter {
+	return &admissionCounter{active: map[string]int{}, rejected: map[string]int{}}
+}
+
+func (c *admissionCounter) StoreCheckActive(backend string, delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active[backend] += delta
+}
+
+func (c *admissionCounter) StoreCheckQueued(delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queued += delta
+}
+
+func (c *admissionCounter) StoreCheckRejected(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rejected[reason]++
+}
+
+func TestAdmission_Reject(t *testing.T) {
+	counter := newAdmissionCounter()
+	a := newAdmission(AdmissionOptions{MaxActive: 2}, counter)
+
+	first, err := a.acquire(context.Background(), "kaldi")
+	require.NoError(t, err)
+
+	_, err = a.acquire(context.Background(), "kaldi")
+	require.NoError(t, err)
+
+	_, err = a.acquire(context.Background(), "kaldi")
+	require.ErrorIs(t, err, ErrChecksBusy)
+	require.Equal(t, 2, counter.active["kaldi"])
+	require.Equal(t, 1, counter.rejected[RejectBusy])
+
+	first()
+	first() // released once
+
+	_, err = a.acquire(context.Background(), "kaldi")
+	require.NoError(t, err)
+	require.Equal(t, 2, counter.active["kaldi"])
+}
+
+func TestAdmission_PerBackend(t *testing.T) {
+	a := newAdmission(AdmissionOptions{MaxActive: 3, MaxPerBackend: 1}, newAdmissionCounter())
+
+	_, err := a.acquire(context.Background(), "first")
+	require.NoError(t, err)
+
+	_, err = a.acquire(context.Background(), "first")
+	require.ErrorIs(t, err, ErrChecksBusy)
+
+	_, err = a.acquire(context.Background(), "second")
+	require.NoError(t, err)
+
+	// the rejected check gave its global slot back
+	_, err = a.acquire(context.Background(), "third")
+	require.NoError(t, err)
+}
+
+func TestAdmission_Queue(t *testing.T) {
+	counter := newAdmissionCounter()
+	a := newAdmission(AdmissionOptions{MaxActive: 1, QueueSize: 1, QueueTimeout: time.Second}, counter)
+
+	release, err := a.acquire(context.Background(), "kaldi")
+	require.NoError(t, err)
+
+	acquired := make(chan error, 1)
+
+	go func() {
+		_, err := a.acquire(context.Background(), "kaldi")
+		acquired <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		counter.mu.Lock()
+		defer counter.mu.Unlock()
+
+		return counter.queued == 1
+	}, time.Second, time.Millisecond)
+
+	// the queue is full
+	_, err = a.acquire(context.Background(), "kaldi")
+	require.ErrorIs(t, err, ErrChecksBusy)
+
+	release()
+	require.NoError(t, <-acquired)
+	require.Equal(t, 0, counter.queued)
+}
+
+func TestAdmission_QueueTimeout(t *testing.T) {
+	counter := newAdmissionCounter()
+	a := newAdmission(AdmissionOptions{MaxActive: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond}, counter)
+
+	_, err := a.acquire(context.Background(), "kaldi")
+	require.NoError(t, err)
+
+	_, err = a.acquire(context.Background(), "kaldi")
+	require.ErrorIs(t, err, ErrQueueTimeout)
+	require.Equal(t, 1, counter.rejected[RejectTimeout])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = a.acquire(ctx, "kaldi")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAdmission_Unlimited(t *testing.T) {
+	a := newAdmission(AdmissionOptions{}, newAdmissionCounter())
+
+	for i := 0; i < 100; i++ {
+		_, err := a.acquire(context.Background(), "kaldi")
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,80 @@
+package botchecker
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Arten331/bot-checker/internal/httpservice/httpwriter"
+	"github.com/Arten331/observability/logger"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionsHandler lists the running checks.
+func (b *BotChecker) SessionsHandler() http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rw := httpwriter.NewJSONResponseWriter()
+		rw.WriteSuccess(w, "", b.sessions.list())
+	}
+
+	return fn
+}
+
+// SessionHandler shows the running check of the call in the id parameter,
+// the server parameter names its asterisk as for the check.
+func (b *BotChecker) SessionHandler() http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rw := httpwriter.NewJSONResponseWriter()
+
+		s, status, err := b.requestedSession(r)
+		if err != nil {
+			rw.WriteError(w, err, status)
+
+			return
+		}
+
+		rw.WriteSuccess(w, "", s.info())
+	}
+
+	return fn
+}
+
+// CancelSessionHandler stops the running check of the call in the id parameter.
+// The call goes on, nothing is published for it.
+func (b *BotChecker) CancelSessionHandler() http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rw := httpwriter.NewJSONResponseWriter()
+
+		s, status, err := b.requestedSession(r)
+		if err != nil {
+			rw.WriteError(w, err, status)
+
+			return
+		}
+
+		s.stop()
+
+		logger.L().Info("check canceled", zap.String("call", s.id), zap.String("remote", r.RemoteAddr))
+
+		rw.WriteSuccess(w, "check canceled", s.info())
+	}
+
+	return fn
+}
+
+// requestedSession finds the check of the call in the id and server parameters.
+func (b *BotChecker) requestedSession(r *http.Request) (*session, int, error) {
+	server, err := b.serverFromQuery(r.URL.Query())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	s, ok := b.sessions.get(server.name(), chi.URLParam(r, "id"))
+	if !ok {
+		return nil, http.StatusNotFound, ErrSessionNotFound
+	}
+
+	return s, http.StatusOK, nil
+}
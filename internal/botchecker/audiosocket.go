@@ -0,0 +1,157 @@
+package botchecker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/Arten331/bot-checker/pkg/audiosocket"
+	"github.com/Arten331/observability/logger"
+	"github.com/CyCoreSystems/ari"
+	"go.uber.org/zap"
+)
+
+// audioSocketVariable holds the UUID given to the AudioSocket application, it maps the connection to its channel:
+//
+//	same => n,Set(AUDIOSOCKET_UUID=<uuid>)
+//	same => n,AudioSocket(${AUDIOSOCKET_UUID},bot-checker:8889)
+//
+// ARI lists it with the channels once ari.conf has channelvars = AUDIOSOCKET_UUID.
+const audioSocketVariable = "AUDIOSOCKET_UUID"
+
+// ChannelLister lists the channels of an asterisk with the variables of channelvars.
+type ChannelLister interface {
+	ListChannels(ctx context.Context) ([]ari.ChannelData, error)
+}
+
+// audioSocketFormat is the only audio the AudioSocket application sends.
+var audioSocketFormat = audio.Format{Codec: audio.CodecSlin, SampleRate: 8000, Channels: 1}
+
+// AudioSocketHandler checks the call streamed by the AudioSocket application.
+func (b *BotChecker) AudioSocketHandler(ctx context.Context, id string, conn net.Conn) error {
+	server, uniqID, err := b.audioSocketCall(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	logger.L().Info("AudioSocket check", zap.String("id", id), zap.String("call", uniqID))
+
+	// the connection is closed on a rejection, the dialplan goes on without the check
+	release, err := b.admission.acquire(ctx, b.KaldiBackend)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	b.Metrics.StoreIvrCheckStart()
+
+	ctx, cancel := context.WithTimeout(ctx, b.Policy.MaxListen)
+	defer cancel()
+
+	b.runCheck(ctx, cancel, checkRequest{
+		ID:        uniqID,
+		Transport: TransportAudioSocket,
+		Remote:    conn.RemoteAddr().String(),
+		Format:    audioSocketFormat,
+		Policy:    b.Policy,
+		Source:    audioSocketSource(conn),
+		Server:    server,
+	})
+
+	return nil
+}
+
+// audioSocketCall finds the channel streaming with id on any asterisk, without ARI the id is the call.
+// An asterisk is asked once, the variable comes with its channel list.
+func (b *BotChecker) audioSocketCall(ctx context.Context, id string) (*AriServer, string, error) {
+	if len(b.serverNames) == 0 {
+		return nil, id, nil
+	}
+
+	for _, name := range b.serverNames {
+		server := b.servers[name]
+		if server.Channels == nil {
+			continue
+		}
+
+		// an asterisk that does not answer must not hold the dialplan
+		listCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		channels, err := server.Channels.ListChannels(listCtx)
+		cancel()
+
+		if err != nil {
+			logger.L().Error("unable list channels", zap.String("server", name), zap.Error(err))
+
+			continue
+		}
+
+		for _, channel := range channels {
+			if strings.EqualFold(channel.ChannelVars[audioSocketVariable], id) {
+				return server, channel.ID, nil
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("no channel with %s=%s", audioSocketVariable, id)
+}
+
+// audioSocketSource reads the audio frames from conn until the call hangs up.
+// The application is released with a hangup frame when the check ends.
+func audioSocketSource(conn net.Conn) audioSource {
+	return func(ctx context.Context, cancel context.CancelFunc, in io.WriteCloser, format audio.Format) audio.Format {
+		go func() {
+			<-ctx.Done()
+
+			_ = audiosocket.WriteFrame(conn, audiosocket.KindHangup, nil)
+			_ = conn.Close()
+		}()
+
+		go func() {
+			defer func() { _ = in.Close() }()
+			defer cancel()
+
+			buf := make([]byte, audiosocket.MaxPayload)
+
+			for {
+				frame, err := audiosocket.ReadFrame(conn, buf)
+				if err != nil {
+					if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+						logger.L().Error("unable read AudioSocket frame", zap.Error(err))
+					}
+
+					return
+				}
+
+				switch frame.Kind {
+				case audiosocket.KindAudio:
+					if _, err = in.Write(frame.Payload); err != nil {
+						return
+					}
+				case audiosocket.KindHangup:
+					logger.L().Debug("AudioSocket hangup")
+
+					return
+				case audiosocket.KindError:
+					code := frame.ErrorCode()
+					if code == audiosocket.ErrorHangup {
+						logger.L().Debug("AudioSocket caller hangup")
+
+						return
+					}
+
+					logger.L().Error("AudioSocket error", zap.Uint8("code", uint8(code)))
+				case audiosocket.KindDTMF:
+					logger.L().Debug("AudioSocket dtmf", zap.ByteString("digit", frame.Payload))
+				default:
+					logger.L().Debug("AudioSocket unknown frame", zap.Uint8("kind", uint8(frame.Kind)))
+				}
+			}
+		}()
+
+		return format
+	}
+}
@@ -0,0 +1,87 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ariclient "github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/Arten331/bot-checker/pkg/ari/aritest"
+	"github.com/CyCoreSystems/ari"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioSocketCall(t *testing.T) {
+	const uuid = "3f2b6f1e-8c4d-4a57-9e2b-0c1d2e3f4a5b"
+
+	main := aritest.NewServer()
+	defer main.Close()
+
+	box2 := aritest.NewServer()
+	defer box2.Close()
+
+	main.AddChannel("call-1", nil)
+	box2.AddChannel("call-2", map[string]string{audioSocketVariable: "other"})
+	box2.AddChannel("call-3", map[string]string{audioSocketVariable: uuid})
+
+	box2Client := ariclient.New(box2.Options())
+
+	b := newTestChecker(t, main, Options{
+		KaldiClient: deafRecognizer{},
+		AriServers:  []AriServer{{Name: "box2", Client: box2Client, Channels: box2Client}},
+	})
+
+	// the UUID is matched in any case
+	server, uniqID, err := b.audioSocketCall(context.Background(), "3F2B6F1E-8C4D-4A57-9E2B-0C1D2E3F4A5B")
+	require.NoError(t, err)
+	require.Equal(t, "box2", server.Name)
+	require.Equal(t, "call-3", uniqID)
+
+	// one list per asterisk, no variable is asked channel by channel
+	require.Equal(t, []string{"GET /channels"}, main.Calls())
+	require.Equal(t, []string{"GET /channels"}, box2.Calls())
+
+	_, _, err = b.audioSocketCall(context.Background(), "00000000-0000-0000-0000-000000000000")
+	require.Error(t, err)
+}
+
+// boundedLister fails at once, it notes whether the list was bounded in time.
+type boundedLister struct {
+	bounded bool
+}
+
+func (l *boundedLister) ListChannels(ctx context.Context) ([]ari.ChannelData, error) {
+	deadline, ok := ctx.Deadline()
+	l.bounded = ok && time.Until(deadline) <= notifyTimeout
+
+	return nil, errors.New("no answer")
+}
+
+func TestAudioSocketCallBounded(t *testing.T) {
+	main := aritest.NewServer()
+	defer main.Close()
+
+	box2 := aritest.NewServer()
+	defer box2.Close()
+
+	box2.AddChannel("call-2", map[string]string{audioSocketVariable: "uuid"})
+
+	stuck := &boundedLister{}
+	box2Client := ariclient.New(box2.Options())
+
+	b := newTestChecker(t, main, Options{
+		KaldiClient: deafRecognizer{},
+		AriServers:  []AriServer{{Name: "box2", Client: box2Client, Channels: box2Client}},
+	})
+	b.servers[DefaultServer].Channels = stuck
+
+	// the failed asterisk is skipped
+	server, uniqID, err := b.audioSocketCall(context.Background(), "uuid")
+	require.NoError(t, err)
+	require.Equal(t, "box2", server.Name)
+	require.Equal(t, "call-2", uniqID)
+	require.True(t, stuck.bounded)
+}
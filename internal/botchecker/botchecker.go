@@ -5,15 +5,19 @@ import (
 	"context"
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Arten331/bot-checker/data/embed"
 	"github.com/Arten331/bot-checker/internal/botchecker/metrics"
 	"github.com/Arten331/bot-checker/internal/domain/phrase"
 	"github.com/Arten331/bot-checker/internal/events"
 	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/bot-checker/internal/recording"
+	"github.com/Arten331/bot-checker/pkg/audio"
 	"github.com/Arten331/bot-checker/pkg/kaldi"
 	"github.com/Arten331/observability/logger"
 	"github.com/CyCoreSystems/ari"
@@ -21,6 +25,8 @@ import (
 	"go.uber.org/zap"
 )
 
+var ErrAriDisconnected = errors.New("ARI is disconnected")
+
 type MetricService interface {
 	Register(prometheus.Collector) error
 	AddMiddleware(func(handler http.Handler) http.Handler)
@@ -30,31 +36,141 @@ type Options struct {
 	EventPublisher        events.EventPublisher
 	MetricService         MetricService
 	StopPhrasesRepository phrase.Repository
-	KaldiClient           *kaldi.Client
+	KaldiClient           kaldi.Recognizer
+	KaldiBackend          string // name of the recognizer for its limit and metrics
+	Admission             AdmissionOptions
+	AriName               string // of the asterisk of AriClient, DefaultServer if empty
 	AriClient             ari.Client
+	MediaChannels         MediaChannels
+	MediaHost             string // advertised to asterisk as the RTP destination
+	MediaListen           string // IP the RTP listener binds to
+	MediaFormat           string // asterisk codec of the externalMedia channel
+	AutoCheck             bool   // check every call entering the ARI application
+	VerdictVariables      bool   // set the outcome variables on the channel
+	VerdictEvent          string // name of the user event with the outcome, none if empty
+	UserEvents            UserEvents
+	Channels              ChannelLister
+	AriServers            []AriServer // more asterisks, a check names its own one
+	DialplanDecides       bool        // report bots and faxes without hanging up
 	SaveRecords           bool
+	Recordings            *recording.Storage
+	AudioBackend          string
+	SampleRate            int // of audio sent to the recognizer
+	RemoteChannel         int // of stereo input, the callee leg
+	PreRoll               time.Duration
+	Policy                Policy // defaults of every check
+	VadEnabled            bool
+	VadSkipSilence        time.Duration
+	BeepEnabled           bool
+	Beep                  audio.BeepOptions
+	SITEnabled            bool
+	SIT                   audio.SITOptions
+	FaxEnabled            bool
+	Fax                   audio.FaxOptions
 }
 
 type BotChecker struct {
 	EventPublisher        events.EventPublisher
 	Metrics               metrics.Metrics
 	stopPhrasesRepository phrase.Repository
-	KaldiClient           *kaldi.Client
+	KaldiClient           kaldi.Recognizer
+	KaldiBackend          string
+	AriName               string
 	AriClient             ari.Client
+	MediaChannels         MediaChannels
+	MediaHost             string // advertised to asterisk as the RTP destination
+	MediaListen           string // IP the RTP listener binds to
+	MediaFormat           string // asterisk codec of the externalMedia channel
+	AutoCheck             bool
+	VerdictVariables      bool
+	VerdictEvent          string
+	UserEvents            UserEvents
+	Channels              ChannelLister
+	DialplanDecides       bool
 	SaveRecords           bool
+	Recordings            *recording.Storage
+	AudioBackend          string
+	SampleRate            int // of audio sent to the recognizer
+	RemoteChannel         int // of stereo input, the callee leg
+	PreRoll               time.Duration
+	Policy                Policy
+	VadEnabled            bool
+	VadSkipSilence        time.Duration
+	BeepEnabled           bool
+	Beep                  audio.BeepOptions
+	SITEnabled            bool
+	SIT                   audio.SITOptions
+	FaxEnabled            bool
+	Fax                   audio.FaxOptions
+
+	admission   *admission
+	sessions    *sessions
+	servers     map[string]*AriServer
+	serverNames []string
 }
 
 func New(o *Options) (*BotChecker, error) {
 	botChecker := BotChecker{
 		stopPhrasesRepository: o.StopPhrasesRepository,
 		KaldiClient:           o.KaldiClient,
+		KaldiBackend:          o.KaldiBackend,
+		AriName:               o.AriName,
 		AriClient:             o.AriClient,
+		MediaChannels:         o.MediaChannels,
+		MediaHost:             o.MediaHost,
+		MediaListen:           o.MediaListen,
+		MediaFormat:           o.MediaFormat,
+		AutoCheck:             o.AutoCheck,
+		VerdictVariables:      o.VerdictVariables,
+		VerdictEvent:          o.VerdictEvent,
+		UserEvents:            o.UserEvents,
+		Channels:              o.Channels,
+		DialplanDecides:       o.DialplanDecides,
+		SaveRecords:           o.SaveRecords,
+		Recordings:            o.Recordings,
+		AudioBackend:          o.AudioBackend,
+		SampleRate:            o.SampleRate,
+		RemoteChannel:         o.RemoteChannel,
+		PreRoll:               o.PreRoll,
+		Policy:                o.Policy,
+		VadEnabled:            o.VadEnabled,
+		VadSkipSilence:        o.VadSkipSilence,
+		BeepEnabled:           o.BeepEnabled,
+		Beep:                  o.Beep,
+		SITEnabled:            o.SITEnabled,
+		SIT:                   o.SIT,
+		FaxEnabled:            o.FaxEnabled,
+		Fax:                   o.Fax,
 		EventPublisher:        o.EventPublisher,
 		Metrics: metrics.Metrics{
 			Service: o.MetricService,
 		},
 	}
 
+	if botChecker.SampleRate == 0 {
+		botChecker.SampleRate = defaultSampleRate
+	}
+
+	if botChecker.Policy.MaxListen <= 0 {
+		botChecker.Policy.MaxListen = defaultMaxListen
+	}
+
+	if err := botChecker.Policy.Validate(); err != nil {
+		return nil, fmt.Errorf("service botchecker: %w", err)
+	}
+
+	if botChecker.MediaFormat == "" {
+		botChecker.MediaFormat = defaultMediaFormat
+	}
+
+	if _, ok := mediaFormats[botChecker.MediaFormat]; !ok {
+		return nil, fmt.Errorf("service botchecker: unsupported media format %q", botChecker.MediaFormat)
+	}
+
+	if botChecker.PreRoll <= 0 {
+		botChecker.PreRoll = defaultPreRoll
+	}
+
 	if botChecker.stopPhrasesRepository == nil {
 		return nil, errors.New("service botchecker require StopPhrasesRepository")
 	}
@@ -63,7 +179,50 @@ func New(o *Options) (*BotChecker, error) {
 		return nil, errors.New("service botchecker require KaldiClient")
 	}
 
+	if botChecker.AriName == "" {
+		botChecker.AriName = DefaultServer
+	}
+
+	if err := botChecker.initServers(o.AriServers); err != nil {
+		return nil, fmt.Errorf("service botchecker: %w", err)
+	}
+
+	if botChecker.VerdictVariables && len(botChecker.servers) == 0 {
+		return nil, errors.New("service botchecker require AriClient to set verdict variables")
+	}
+
+	for _, name := range botChecker.serverNames {
+		server := botChecker.servers[name]
+
+		if botChecker.AutoCheck && !botChecker.mediaConfigured(server) {
+			return nil, fmt.Errorf("service botchecker require ARI media of %s for the auto check", name)
+		}
+
+		if botChecker.VerdictEvent != "" && server.UserEvents == nil {
+			return nil, fmt.Errorf("service botchecker require UserEvents of %s to raise the verdict event", name)
+		}
+	}
+
+	if botChecker.SaveRecords && botChecker.Recordings == nil {
+		return nil, errors.New("service botchecker require Recordings to save records")
+	}
+
 	botChecker.Metrics.Register()
+
+	botChecker.eachServer(func(s *AriServer) {
+		if state, ok := s.Client.(ConnectionState); ok {
+			botChecker.Metrics.RegisterAriConnected(s.Name, state.Connected)
+		}
+	})
+
+	admission := o.Admission
+	if admission.QueueSize > 0 && admission.QueueTimeout <= 0 {
+		admission.QueueTimeout = defaultQueueTimeout
+	}
+
+	botChecker.admission = newAdmission(admission, &botChecker.Metrics)
+	botChecker.sessions = newSessions()
+
 	//nolint:gocritic // example, how add middleware to prometheus
 	/*botChecker.metrics.service.AddMiddleware(func(handler http.Handle) http.Handle {
 		fn := func(w http.ResponseWriter, r *http.Request) {
@@ -77,7 +236,7 @@ func New(o *Options) (*BotChecker, error) {
 	return &botChecker, nil
 }
 
-func (b *BotChecker) Run(_ context.Context, cancelFunc context.CancelFunc) {
+func (b *BotChecker) Run(ctx context.Context, cancelFunc context.CancelFunc) {
 	err := b.loadStopPhrases()
 	if err != nil {
 		logger.L().Error("failed run botchecker service", zap.Error(err))
@@ -85,16 +244,44 @@ func (b *BotChecker) Run(_ context.Context, cancelFunc context.CancelFunc) {
 		cancelFunc()
 	}
 
-	if b.AriClient != nil {
-		info, err := b.AriClient.Asterisk().Info(nil)
-		if err != nil {
-			logger.L().Error("Failed to get Asterisk Info", zap.Error(err))
+	b.eachServer(func(s *AriServer) {
+		go b.watchHangups(ctx, s)
+
+		if b.AutoCheck {
+			go b.runStasis(ctx, s)
+		}
+	})
+}
+
+// ConnectionState is an ARI client watching its connection to asterisk.
+type ConnectionState interface {
+	Connected() bool
+}
+
+// Ready fails while ARI of the default asterisk is down or of every one, hangups and call variables
+// would fail silently. The other asterisks report their state in the ari_connected metric only,
+// one of them down does not stop the checks of the others.
+func (b *BotChecker) Ready() error {
+	down := 0
 
-			return
+	for _, name := range b.serverNames {
+		state, ok := b.servers[name].Client.(ConnectionState)
+		if !ok || state.Connected() {
+			continue
 		}
 
-		logger.L().Info("Asterisk Info", zap.Reflect("info", info))
+		if name == b.AriName {
+			return fmt.Errorf("%w: %s", ErrAriDisconnected, name)
+		}
+
+		down++
+	}
+
+	if down > 0 && down == len(b.serverNames) {
+		return fmt.Errorf("%w: every asterisk", ErrAriDisconnected)
 	}
+
+	return nil
 }
 
 func (b *BotChecker) loadStopPhrases() error {
@@ -0,0 +1,37 @@
+package botchecker
+
+import "errors"
+
+// call is the checked channel and its parties.
+type call struct {
+	ID     string
+	Caller string
+	DNID   string
+
+	server *AriServer // nil without ARI
+}
+
+// lookupCall reads the parties from the channel variables, they stay empty without ARI.
+// It has to run while the channel is up.
+func (b *BotChecker) lookupCall(server *AriServer, uniqID string) call {
+	c := call{ID: uniqID, server: server}
+
+	if server == nil {
+		return c
+	}
+
+	channel := server.channel(uniqID)
+
+	c.Caller, _ = channel.GetVariable("CALLERID(num)")
+	c.DNID, _ = channel.GetVariable("DNID")
+
+	return c
+}
+
+func (c call) hangup() error {
+	if c.server == nil {
+		return errors.New("no ARI to hang up the call")
+	}
+
+	return c.server.channel(c.ID).Hangup()
+}
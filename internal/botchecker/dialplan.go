@@ -0,0 +1,159 @@
+package botchecker
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Arten331/observability/logger"
+	"go.uber.org/zap"
+)
+
+// channel variables with the outcome of a check, set for the dialplan.
+const (
+	VarResult     = "BOTCHECK_RESULT"
+	VarCategory   = "BOTCHECK_CATEGORY"
+	VarPhrase     = "BOTCHECK_PHRASE"
+	VarConfidence = "BOTCHECK_CONFIDENCE"
+	VarDuration   = "BOTCHECK_DURATION" // milliseconds of the checked audio
+)
+
+// UserEvents raises ARI user events of a channel, AMI sees them as UserEvent.
+type UserEvents interface {
+	UserEvent(ctx context.Context, name, channelID string, variables map[string]string) error
+}
+
+// Outcome is the verdict of a check as the dialplan sees it.
+type Outcome struct {
+	Result     string
+	Category   string
+	Phrase     string
+	Confidence float64
+	Duration   time.Duration
+}
+
+// outcome describes verdict v of a call with transcript text. A match is certain,
+// a human is as certain as the transcript is far from the closest stop phrase.
+func (b *BotChecker) outcome(v Verdict, text string, duration time.Duration) Outcome {
+	o := Outcome{
+		Result:     verdictResult(v),
+		Category:   v.Category,
+		Phrase:     v.PhraseText(),
+		Confidence: 1,
+		Duration:   duration,
+	}
+
+	if o.Result != ResultHuman {
+		return o
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		// nothing was heard to tell
+		o.Confidence = 0
+
+		return o
+	}
+
+	if closest, err := b.stopPhrasesRepository.FindCloser(text); err == nil {
+		o.Confidence = 1 - similarity(text, closest.Phrase)
+	}
+
+	return o
+}
+
+func (o Outcome) variables() map[string]string {
+	return map[string]string{
+		VarResult:     o.Result,
+		VarCategory:   o.Category,
+		VarPhrase:     o.Phrase,
+		VarConfidence: strconv.FormatFloat(o.Confidence, 'f', 2, 64),
+		VarDuration:   strconv.FormatInt(o.Duration.Milliseconds(), 10),
+	}
+}
+
+// publishOutcome sets the outcome variables on the channel and raises the verdict user event.
+func (b *BotChecker) publishOutcome(c call, o Outcome) {
+	uniqID, variables := c.ID, o.variables()
+
+	if b.VerdictVariables {
+		channel := c.server.channel(uniqID)
+
+		for _, name := range []string{VarResult, VarCategory, VarPhrase, VarConfidence, VarDuration} {
+			if err := channel.SetVariable(name, variables[name]); err != nil {
+				logger.L().Error("unable set check variable",
+					zap.String("call", uniqID), zap.String("variable", name), zap.Error(err))
+
+				break
+			}
+		}
+	}
+
+	if b.VerdictEvent != "" {
+		// the check context may be done here
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+
+		if err := c.server.UserEvents.UserEvent(ctx, b.VerdictEvent, uniqID, variables); err != nil {
+			logger.L().Error("unable raise verdict event", zap.String("call", uniqID), zap.Error(err))
+		}
+	}
+}
+
+// similarity of text to a stop phrase from 0 to 1, the text is cut to the length of the phrase.
+func similarity(text, stopPhrase string) float64 {
+	a, b := []rune(text), []rune(stopPhrase)
+	if len(a) > len(b) {
+		a = a[:len(b)]
+	}
+
+	longest := len(b)
+	if len(a) > longest {
+		longest = len(a)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
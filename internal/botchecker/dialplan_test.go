@@ -0,0 +1,60 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/domain/phrase"
+	"github.com/Arten331/bot-checker/internal/domain/phrase/memdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, similarity("абонент недоступен", "абонент недоступен"))
+	require.Equal(t, 1.0, similarity("абонент недоступен попробуйте позднее", "абонент недоступен"))
+	require.InDelta(t, 0.5, similarity("кот", "кошк"), 0.001)
+	require.Equal(t, 0.0, similarity("да", "нет"))
+}
+
+func TestOutcomeVariables(t *testing.T) {
+	o := Outcome{
+		Result:     ResultBot,
+		Category:   "voicemail",
+		Phrase:     "оставьте сообщение",
+		Confidence: 1,
+		Duration:   3420 * time.Millisecond,
+	}
+
+	require.Equal(t, map[string]string{
+		VarResult:     ResultBot,
+		VarCategory:   "voicemail",
+		VarPhrase:     "оставьте сообщение",
+		VarConfidence: "1.00",
+		VarDuration:   "3420",
+	}, o.variables())
+}
+
+func TestOutcome(t *testing.T) {
+	repo, err := memdb.NewPhraseMemDBRepository()
+	require.NoError(t, err)
+
+	p := phrase.New("оставьте сообщение", "voicemail")
+	require.NoError(t, repo.Load([]*phrase.StopPhrase{p}))
+
+	b := &BotChecker{stopPhrasesRepository: &repo}
+
+	o := b.outcome(speechVerdict(p), "оставьте сообщение после сигнала", time.Second)
+	require.Equal(t, ResultBot, o.Result)
+	require.Equal(t, "voicemail", o.Category)
+	require.Equal(t, 1.0, o.Confidence)
+
+	o = b.outcome(Verdict{}, " ", time.Second)
+	require.Equal(t, ResultHuman, o.Result)
+	require.Equal(t, 0.0, o.Confidence)
+
+	o = b.outcome(Verdict{}, "оставьте", time.Second)
+	require.Equal(t, ResultHuman, o.Result)
+	require.InDelta(t, 10.0/18, o.Confidence, 0.001)
+}
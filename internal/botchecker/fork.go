@@ -0,0 +1,273 @@
+package botchecker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/Arten331/observability/logger"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"go.uber.org/zap"
+)
+
+// maxTextFrame limits metadata messages, audio is never sent as text.
+const maxTextFrame = 64 << 10
+
+// forkConn writes whole frames to the AudioFork connection one at a time,
+// the reader answers control frames while the check replies and closes the connection.
+type forkConn struct {
+	net.Conn
+
+	mu     sync.Mutex // held for a whole frame
+	closed bool
+}
+
+func newForkConn(conn net.Conn) *forkConn {
+	return &forkConn{Conn: conn}
+}
+
+// Write is called by frame writers holding mu.
+func (c *forkConn) Write(p []byte) (int, error) {
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+
+	return c.Conn.Write(p)
+}
+
+// control answers a control frame, a close frame is answered and ends the connection.
+func (c *forkConn) control(header ws.Header, r io.Reader, handler wsutil.FrameHandlerFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := handler(header, r)
+
+	if header.OpCode == ws.OpClose {
+		c.closed = true
+	}
+
+	return err
+}
+
+// writeJSON sends v as a text frame.
+func (c *forkConn) writeJSON(v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(notifyTimeout))
+
+	return ws.WriteFrame(c, ws.NewTextFrame(payload))
+}
+
+// close sends a close frame with code unless one was sent already and closes the connection.
+func (c *forkConn) close(code ws.StatusCode, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		_ = c.Conn.SetWriteDeadline(time.Now().Add(notifyTimeout))
+		_ = ws.WriteFrame(c, ws.NewCloseFrame(ws.NewCloseFrameBody(code, reason)))
+		c.closed = true
+	}
+
+	_ = c.Conn.Close()
+}
+
+// readAudioForkMessages writes binary frames to inAudio until the connection ends.
+// It returns after the first data frame, with the format declared by it if that frame is JSON text.
+// Later text frames are passed to onMetadata. The connection is closed by its owner.
+func readAudioForkMessages(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	conn *forkConn,
+	inAudio io.WriteCloser,
+	format audio.Format,
+	onMetadata func(payload []byte),
+) audio.Format {
+	controlHandler := wsutil.ControlFrameHandler(conn, ws.StateServerSide)
+	reader := &wsutil.Reader{
+		Source:    conn,
+		State:     ws.StateServerSide,
+		CheckUTF8: true,
+		OnIntermediate: func(header ws.Header, r io.Reader) error {
+			return conn.control(header, r, controlHandler)
+		},
+	}
+
+	startSox := make(chan audio.Format, 1)
+	started := false
+
+	start := func(f audio.Format) {
+		if !started {
+			started = true
+			startSox <- f
+		}
+	}
+
+	go func() {
+		defer func() { _ = inAudio.Close() }()
+		defer cancel()
+
+		for {
+			header, err := reader.NextFrame()
+			if err != nil {
+				logForkError(ctx, "unable read ws frame", err)
+				conn.close(ws.StatusProtocolError, "")
+
+				return
+			}
+
+			switch {
+			case header.OpCode.IsControl():
+				if err = conn.control(header, reader, controlHandler); err != nil {
+					logForkError(ctx, "AudioFork closed", err)
+
+					return
+				}
+			case header.OpCode == ws.OpText:
+				payload, err := io.ReadAll(io.LimitReader(reader, maxTextFrame+1))
+				if err == nil && len(payload) > maxTextFrame {
+					err = errors.New("text frame too large")
+				}
+
+				if err != nil {
+					logForkError(ctx, "unable read ws text frame", err)
+					conn.close(ws.StatusMessageTooBig, "")
+
+					return
+				}
+
+				if started {
+					onMetadata(payload)
+
+					continue
+				}
+
+				declared, err := formatFromFrame(payload, format)
+				if err != nil {
+					logger.L().Error("invalid audio format frame", zap.Error(err))
+				}
+
+				start(declared)
+			default:
+				start(format)
+
+				// continuation frames of the message are read through
+				if _, err = io.Copy(inAudio, reader); err != nil {
+					logForkError(ctx, "unable read ws message", err)
+
+					return
+				}
+			}
+		}
+	}()
+
+	select {
+	case declared := <-startSox:
+		return declared
+	case <-ctx.Done():
+		return format
+	}
+}
+
+// logForkError logs reader errors unless they come from the end of the check or a normal close.
+func logForkError(ctx context.Context, msg string, err error) {
+	var closed wsutil.ClosedError
+
+	switch {
+	case ctx.Err() != nil:
+		return
+	case errors.As(err, &closed) && (closed.Code == ws.StatusNormalClosure || closed.Code == ws.StatusGoingAway):
+		logger.L().Debug(msg, zap.Error(err))
+	case errors.Is(err, io.EOF):
+		logger.L().Debug(msg, zap.Error(err))
+	default:
+		logger.L().Error(msg, zap.Error(err))
+	}
+}
+
+const (
+	forkMessageVerdict    = "verdict"
+	forkMessageTranscript = "transcript"
+)
+
+// forkVerdict is the text frame with the outcome, sent before the connection is closed.
+type forkVerdict struct {
+	Type       string `json:"type"`
+	CallID     string `json:"id"`
+	IsBot      bool   `json:"is_bot"`
+	Action     string `json:"action,omitempty"`
+	Source     string `json:"source,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Phrase     string `json:"phrase,omitempty"`
+	Transcript string `json:"transcript"`
+}
+
+// forkTranscript is a text frame with a recognizer result, sent in the interim mode.
+type forkTranscript struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Final bool   `json:"final"`
+}
+
+// forkReplier answers the AudioFork client with text frames.
+type forkReplier struct {
+	conn    *forkConn
+	callID  string
+	interim bool
+}
+
+func (r *forkReplier) transcript(msg models.KaldiMessage) {
+	if !r.interim || len(msg.Text) == 0 {
+		return
+	}
+
+	err := r.conn.writeJSON(forkTranscript{
+		Type:  forkMessageTranscript,
+		Text:  string(msg.Text),
+		Final: msg.IsFinal,
+	})
+	if err != nil {
+		logger.L().Debug("unable send transcript to AudioFork", zap.Error(err))
+	}
+}
+
+func (r *forkReplier) verdict(v Verdict, text string) {
+	err := r.conn.writeJSON(forkVerdict{
+		Type:       forkMessageVerdict,
+		CallID:     r.callID,
+		IsBot:      v.IsBot,
+		Action:     v.Action,
+		Source:     v.Source,
+		Category:   v.Category,
+		Detail:     v.Detail,
+		Phrase:     v.PhraseText(),
+		Transcript: text,
+	})
+	switch {
+	case err == nil:
+	case clientGone(err):
+		logger.L().Debug("unable send verdict to AudioFork", zap.Error(err))
+	default:
+		logger.L().Error("unable send verdict to AudioFork", zap.Error(err))
+	}
+}
+
+// clientGone tells whether a write failed because the client closed or dropped the connection.
+func clientGone(err error) bool {
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
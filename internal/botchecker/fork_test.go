@@ -0,0 +1,221 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/gobwas/ws"
+	"github.com/stretchr/testify/require"
+)
+
+type audioBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed chan struct{}
+}
+
+func (b *audioBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *audioBuffer) Close() error {
+	close(b.closed)
+
+	return nil
+}
+
+func (b *audioBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+// writeClientFrame writes a masked frame, as a client does.
+func writeClientFrame(t *testing.T, w io.Writer, fin bool, op ws.OpCode, payload []byte) {
+	t.Helper()
+
+	frame := ws.MaskFrameInPlace(ws.NewFrame(op, fin, append([]byte(nil), payload...)))
+	require.NoError(t, ws.WriteFrame(w, frame))
+}
+
+func TestReadAudioForkMessages(t *testing.T) {
+	server, client := net.Pipe()
+	conn := newForkConn(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	metadata := make(chan []byte, 1)
+	in := &audioBuffer{closed: make(chan struct{})}
+	formatCh := make(chan audio.Format, 1)
+
+	go func() {
+		formatCh <- readAudioForkMessages(ctx, cancel, conn, in, audio.Format{
+			Codec:      audio.CodecSlin,
+			SampleRate: 8000,
+			Channels:   1,
+		}, func(payload []byte) { metadata <- payload })
+	}()
+
+	// control frames answered by the server are read while the client writes
+	frames := make(chan ws.Frame, 4)
+
+	go func() {
+		for {
+			frame, err := ws.ReadFrame(client)
+			if err != nil {
+				close(frames)
+
+				return
+			}
+
+			frames <- frame
+		}
+	}()
+
+	writeClientFrame(t, client, true, ws.OpText, []byte(`{"codec":"ulaw"}`))
+	require.Equal(t, audio.CodecULaw, (<-formatCh).Codec)
+
+	// a fragmented message with a ping in between
+	writeClientFrame(t, client, false, ws.OpBinary, []byte{1, 2})
+	writeClientFrame(t, client, true, ws.OpPing, []byte("ping"))
+	writeClientFrame(t, client, true, ws.OpContinuation, []byte{3, 4})
+
+	pong := <-frames
+	require.Equal(t, ws.OpPong, pong.Header.OpCode)
+	require.Equal(t, []byte("ping"), pong.Payload)
+
+	writeClientFrame(t, client, true, ws.OpText, []byte(`{"event":"dtmf"}`))
+	require.Equal(t, []byte(`{"event":"dtmf"}`), <-metadata)
+	require.Equal(t, []byte{1, 2, 3, 4}, in.Bytes())
+
+	replier := &forkReplier{conn: conn, callID: "call-1"}
+	replier.verdict(Verdict{IsBot: true, Action: ActionHangupBot, Source: SourceBeep}, "hello")
+
+	reply := <-frames
+	require.Equal(t, ws.OpText, reply.Header.OpCode)
+	require.False(t, reply.Header.Masked)
+
+	var v forkVerdict
+
+	require.NoError(t, json.Unmarshal(reply.Payload, &v))
+	require.Equal(t, forkVerdict{
+		Type:       forkMessageVerdict,
+		CallID:     "call-1",
+		IsBot:      true,
+		Action:     ActionHangupBot,
+		Source:     SourceBeep,
+		Transcript: "hello",
+	}, v)
+
+	conn.close(ws.StatusNormalClosure, "check finished")
+
+	closing := <-frames
+	require.Equal(t, ws.OpClose, closing.Header.OpCode)
+
+	code, reason := ws.ParseCloseFrameData(closing.Payload)
+	require.Equal(t, ws.StatusNormalClosure, code)
+	require.Equal(t, "check finished", reason)
+
+	<-in.closed
+	require.Error(t, ctx.Err())
+}
+
+func TestReadAudioForkMessages_ClientClose(t *testing.T) {
+	server, client := net.Pipe()
+	conn := newForkConn(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	in := &audioBuffer{closed: make(chan struct{})}
+
+	go readAudioForkMessages(ctx, cancel, conn, in, audio.Format{}, func([]byte) {})
+
+	writeClientFrame(t, client, true, ws.OpBinary, []byte{1})
+	writeClientFrame(t, client, true, ws.OpClose, ws.NewCloseFrameBody(ws.StatusGoingAway, ""))
+
+	reply, err := ws.ReadFrame(client)
+	require.NoError(t, err)
+	require.Equal(t, ws.OpClose, reply.Header.OpCode)
+
+	<-in.closed
+	require.Error(t, ctx.Err())
+
+	// nothing is written after the close frame
+	require.ErrorIs(t, conn.writeJSON(forkTranscript{}), net.ErrClosed)
+}
+
+func TestReadAudioForkMessages_TextTooLarge(t *testing.T) {
+	server, client := net.Pipe()
+	conn := newForkConn(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	in := &audioBuffer{closed: make(chan struct{})}
+
+	go readAudioForkMessages(ctx, cancel, conn, in, audio.Format{}, func([]byte) {})
+
+	writeClientFrame(t, client, true, ws.OpBinary, []byte{1})
+	writeClientFrame(t, client, true, ws.OpText, bytes.Repeat([]byte("a"), maxTextFrame+1))
+
+	reply, err := ws.ReadFrame(client)
+	require.NoError(t, err)
+	require.Equal(t, ws.OpClose, reply.Header.OpCode)
+
+	code, _ := ws.ParseCloseFrameData(reply.Payload)
+	require.Equal(t, ws.StatusMessageTooBig, code)
+
+	<-in.closed
+	require.Error(t, ctx.Err())
+}
+
+func TestClientGone(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = listener.Close() }()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	server, err := listener.Accept()
+	require.NoError(t, err)
+
+	conn := newForkConn(server)
+	defer conn.close(ws.StatusNormalClosure, "")
+
+	// a stalled client is not gone
+	_ = server.SetWriteDeadline(time.Now().Add(-time.Second))
+	_, err = server.Write([]byte{1})
+	require.Error(t, err)
+	require.False(t, clientGone(err))
+
+	// the client drops the connection, the writes fail soon
+	require.NoError(t, client.Close())
+	_ = server.SetWriteDeadline(time.Time{})
+
+	require.Eventually(t, func() bool {
+		_, err = server.Write(make([]byte, 1024))
+
+		return err != nil
+	}, 5*time.Second, 5*time.Millisecond)
+	require.True(t, clientGone(err), err)
+
+	conn.close(ws.StatusNormalClosure, "")
+	require.True(t, clientGone(conn.writeJSON(forkTranscript{})))
+}
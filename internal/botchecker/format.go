@@ -0,0 +1,107 @@
+package botchecker
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+const (
+	defaultPreRoll     = 2 * time.Second
+	defaultMediaFormat = "ulaw"
+	defaultCodec       = audio.CodecSlin
+	defaultSampleRate  = 8000
+	defaultChannels    = 1
+)
+
+// streamFormat is the audio format declared by the client in the query or in the first text frame.
+type streamFormat struct {
+	Codec    string `json:"codec"`
+	Rate     int    `json:"rate"`
+	Channels int    `json:"channels"`
+}
+
+// formatFromQuery reads codec, rate and channels parameters, absent ones keep the AudioFork defaults.
+func formatFromQuery(query url.Values) (audio.Format, error) {
+	f := streamFormat{Codec: query.Get("codec")}
+
+	for key, dst := range map[string]*int{"rate": &f.Rate, "channels": &f.Channels} {
+		if v := query.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return audio.Format{}, err
+			}
+
+			*dst = n
+		}
+	}
+
+	format := f.merge(audio.Format{
+		Codec:      defaultCodec,
+		SampleRate: defaultSampleRate,
+		Channels:   defaultChannels,
+	})
+
+	return format, format.Validate()
+}
+
+// interimFromQuery reads the interim parameter asking for transcripts while the check runs.
+func interimFromQuery(query url.Values) (bool, error) {
+	v := query.Get("interim")
+	if v == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(v)
+}
+
+// formatFromFrame applies a JSON text frame to the format declared in the query.
+func formatFromFrame(payload []byte, format audio.Format) (audio.Format, error) {
+	var f streamFormat
+
+	if err := json.Unmarshal(payload, &f); err != nil {
+		return format, err
+	}
+
+	merged := f.merge(format)
+	if err := merged.Validate(); err != nil {
+		return format, err
+	}
+
+	return merged, nil
+}
+
+// inputFormat is the audio the pipeline takes without decoding.
+func (b *BotChecker) inputFormat() audio.Format {
+	return audio.Format{
+		Codec:      audio.CodecSlin,
+		SampleRate: b.SampleRate,
+		Channels:   1,
+	}
+}
+
+// preRollSize is the buffer for PreRoll of the format declared in the query.
+func (b *BotChecker) preRollSize(format audio.Format) int {
+	bytesPerSecond := format.SampleRate * format.Channels * format.SampleSize()
+
+	return int(b.PreRoll.Seconds() * float64(bytesPerSecond))
+}
+
+func (f streamFormat) merge(format audio.Format) audio.Format {
+	if f.Codec != "" {
+		format.Codec = f.Codec
+	}
+
+	if f.Rate != 0 {
+		format.SampleRate = f.Rate
+	}
+
+	if f.Channels != 0 {
+		format.Channels = f.Channels
+	}
+
+	return format
+}
@@ -0,0 +1,109 @@
+package botchecker
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Arten331/observability/logger"
+	"github.com/CyCoreSystems/ari"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// causeNames are the asterisk names of the usual Q.850 causes, ChannelHangupRequest carries only the code.
+var causeNames = map[int]string{
+	16:  "Normal Clearing",
+	17:  "User busy",
+	18:  "No user responding",
+	19:  "User alerting, no answer",
+	21:  "Call Rejected",
+	31:  "Normal, unspecified",
+	34:  "Circuit/channel congestion",
+	38:  "Network out of order",
+	127: "Interworking, unspecified",
+}
+
+// hangupCause is the Q.850 cause of a call that hung up during its check.
+type hangupCause struct {
+	Code int
+	Text string
+}
+
+func newHangupCause(code int, text string) hangupCause {
+	if text == "" {
+		text = causeNames[code]
+	}
+
+	return hangupCause{Code: code, Text: text}
+}
+
+func (c hangupCause) String() string {
+	if c.Text == "" {
+		return strconv.Itoa(c.Code)
+	}
+
+	return c.Text
+}
+
+func (c hangupCause) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddInt("code", c.Code)
+	encoder.AddString("text", c.Text)
+
+	return nil
+}
+
+// watchHangups abandons the checks of the calls hung up on server until ctx is done.
+func (b *BotChecker) watchHangups(ctx context.Context, server *AriServer) {
+	sub := server.Client.Bus().Subscribe(nil, ari.Events.ChannelHangupRequest, ari.Events.ChannelDestroyed)
+	defer sub.Cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			switch e := ev.(type) {
+			case *ari.ChannelHangupRequest:
+				b.abandonCheck(server, e.Channel.ID, newHangupCause(e.Cause, ""))
+			case *ari.ChannelDestroyed:
+				b.abandonCheck(server, e.Channel.ID, newHangupCause(e.Cause, e.CauseTxt))
+			}
+		}
+	}
+}
+
+// abandonCheck stops the running check of the call, it ends with an abandoned verdict.
+func (b *BotChecker) abandonCheck(server *AriServer, uniqID string, cause hangupCause) {
+	s, ok := b.sessions.get(server.Name, uniqID)
+	if !ok {
+		return
+	}
+
+	if s.abandon(cause) {
+		logger.L().Info("call hung up during the check", zap.String("call", uniqID), zap.Object("cause", cause))
+	}
+}
+
+// followHangup subscribes the application to the events of a channel checked outside of it,
+// the returned func drops the subscription.
+func followHangup(server *AriServer, uniqID string) func() {
+	key := ari.NewKey(ari.ApplicationKey, server.Client.ApplicationName())
+	source := "channel:" + uniqID
+
+	if err := server.Client.Application().Subscribe(key, source); err != nil {
+		logger.L().Warn("unable follow the call hangup", zap.String("call", uniqID), zap.Error(err))
+
+		return func() {}
+	}
+
+	return func() {
+		// fails once the channel is gone
+		if err := server.Client.Application().Unsubscribe(key, source); err != nil {
+			logger.L().Debug("unable unsubscribe from the call", zap.String("call", uniqID), zap.Error(err))
+		}
+	}
+}
@@ -4,248 +4,609 @@ import (
 	"context"
 	"errors"
 	"io"
-	"net"
 	"net/http"
-	"os"
+	"strconv"
 	"time"
 
 	"github.com/Arten331/bot-checker/internal/domain/phrase"
 	checkevents "github.com/Arten331/bot-checker/internal/events/bot_checker"
+	"github.com/Arten331/bot-checker/internal/httpservice/httpwriter"
+	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/bot-checker/internal/recording"
 	"github.com/Arten331/bot-checker/pkg/audio"
 	commands2 "github.com/Arten331/bot-checker/pkg/audio/commands"
 	"github.com/Arten331/observability/logger"
-	"github.com/CyCoreSystems/ari"
 	"github.com/go-chi/chi/v5"
 	"github.com/gobwas/ws"
 	"go.uber.org/zap"
 )
 
+const notifyTimeout = 5 * time.Second
+
+const (
+	TransportAudioFork   = "audiofork"
+	TransportARI         = "ari"
+	TransportAudioSocket = "audiosocket"
+)
+
+// checkRequest is a check as started by one of the transports.
+type checkRequest struct {
+	ID        string
+	Transport string
+	Remote    string
+	Format    audio.Format
+	Policy    Policy
+	Source    audioSource
+	Replier   checkReplier // optional
+	Server    *AriServer   // of the call, nil without ARI
+}
+
+// checkReplier answers the client of a check over its own connection.
+type checkReplier interface {
+	transcript(msg models.KaldiMessage)
+	verdict(v Verdict, text string)
+}
+
 func (b *BotChecker) CheckBotHandler() http.HandlerFunc {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		var err error
 
 		uniqID := chi.URLParam(r, "uniqID")
 
-		b.Metrics.StoreIvrCheckStart()
+		format, err := formatFromQuery(r.URL.Query())
+		if err != nil {
+			logger.L().Error("invalid audio format", zap.Error(err))
 
-		conn, _, _, err := ws.UpgradeHTTP(r, w)
+			rw := httpwriter.NewJSONResponseWriter()
+			rw.WriteError(w, err, http.StatusBadRequest)
+
+			return
+		}
+
+		policy, err := b.Policy.withQuery(r.URL.Query())
 		if err != nil {
-			logger.L().Error("handshake error", zap.Error(err))
+			logger.L().Error("invalid check policy", zap.Error(err))
+
+			rw := httpwriter.NewJSONResponseWriter()
+			rw.WriteError(w, err, http.StatusBadRequest)
 
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(r.Context(), time.Second*120)
-		defer cancel()
+		interim, err := interimFromQuery(r.URL.Query())
+		if err != nil {
+			logger.L().Error("invalid interim parameter", zap.Error(err))
+
+			rw := httpwriter.NewJSONResponseWriter()
+			rw.WriteError(w, err, http.StatusBadRequest)
 
-		out, err := b.soxFlow(ctx, cancel, conn)
+			return
+		}
+
+		server, err := b.serverFromQuery(r.URL.Query())
 		if err != nil {
-			logger.L().Error("Failed create audio pipe", zap.Error(err))
+			logger.L().Error("invalid asterisk server", zap.Error(err))
 
-			cancel()
+			rw := httpwriter.NewJSONResponseWriter()
+			rw.WriteError(w, err, http.StatusBadRequest)
 
 			return
 		}
 
-		defer func() {
-			file, ok := out.(*os.File)
-			if ok {
-				_ = os.Remove(file.Name())
-			}
-		}()
+		// refused before the upgrade, the running check keeps the call
+		if _, ok := b.sessions.get(server.name(), uniqID); ok {
+			logger.L().Warn("bot check rejected", zap.String("call", uniqID), zap.Error(ErrSessionExists))
 
-		resCh, errCh := b.KaldiClient.ProcessAudio(ctx, out)
+			rw := httpwriter.NewJSONResponseWriter()
+			rw.WriteError(w, ErrSessionExists, http.StatusConflict)
 
-		isBot, stopPhrase, err := b.Check(ctx, cancel, resCh, errCh)
-		if errors.Is(err, phrase.ErrPhraseNotFound) {
-			logger.L().Info("bot is not finded", zap.Error(err))
+			return
 		}
 
+		// rejected before the upgrade, the dialplan goes on without the check
+		release, err := b.admission.acquire(r.Context(), b.KaldiBackend)
 		if err != nil {
-			logger.L().Info("err find stop phrase", zap.Error(err))
+			logger.L().Warn("bot check rejected", zap.String("call", uniqID), zap.Error(err))
 
-			cancel()
+			rw := httpwriter.NewJSONResponseWriter()
+			rw.WriteError(w, err, http.StatusServiceUnavailable)
 
 			return
 		}
+		defer release()
 
-		if isBot {
-			logger.L().Info("found a bot", zap.Object("phrase", stopPhrase))
+		b.Metrics.StoreIvrCheckStart()
 
-			b.HangupBot(ctx, uniqID, stopPhrase)
-			<-time.After(time.Second * 1)
+		netConn, _, _, err := ws.UpgradeHTTP(r, w)
+		if err != nil {
+			logger.L().Error("handshake error", zap.Error(err))
 
 			return
 		}
 
-		logger.L().Info("Bot not found")
+		conn := newForkConn(netConn)
+		defer conn.close(ws.StatusNormalClosure, "check finished")
 
-		<-ctx.Done()
+		ctx, cancel := context.WithTimeout(r.Context(), policy.MaxListen)
+		defer cancel()
 
-		return
+		b.runCheck(ctx, cancel, checkRequest{
+			ID:        uniqID,
+			Transport: TransportAudioFork,
+			Remote:    r.RemoteAddr,
+			Format:    format,
+			Policy:    policy,
+			Source:    forkSource(conn, uniqID),
+			Replier:   &forkReplier{conn: conn, callID: uniqID, interim: interim},
+			Server:    server,
+		})
 	}
 
 	return fn
 }
 
-func (b *BotChecker) HangupBot(ctx context.Context, uniqID string, stopPhrase *phrase.StopPhrase) {
-	channel := b.AriClient.Channel().Get(&ari.Key{
-		Kind: ari.ChannelKey,
-		ID:   uniqID,
-	})
+// runCheck recognizes the call audio read from the request source and acts on the verdict.
+// The check is listed in the sessions while it runs. It reports whether the call was hung up.
+func (b *BotChecker) runCheck(ctx context.Context, cancel context.CancelFunc, req checkRequest) bool {
+	uniqID, policy := req.ID, req.Policy
+
+	logger.L().Debug("check policy", zap.String("call", uniqID), zap.Object("policy", policy))
+
+	started := time.Now()
+	text := &transcript{}
+
+	if req.Replier != nil {
+		text.onMessage = req.Replier.transcript
+	}
+
+	s := &session{
+		id:        uniqID,
+		transport: req.Transport,
+		server:    req.Server.name(),
+		remote:    req.Remote,
+		backend:   b.KaldiBackend,
+		started:   started,
+		text:      text,
+		cancel:    cancel,
+	}
+
+	if err := b.sessions.add(s); err != nil {
+		logger.L().Error("unable start check", zap.String("call", uniqID), zap.Error(err))
+
+		return false
+	}
+	defer b.sessions.remove(s)
+
+	// asterisk drops the subscription with the channel, it is dropped here for the calls left up
+	channelUp := true
+
+	if req.Server != nil {
+		unfollow := followHangup(req.Server, uniqID)
+
+		defer func() {
+			if channelUp {
+				unfollow()
+			}
+		}()
+	}
+
+	// the parties are read while the channel is up
+	c := b.lookupCall(req.Server, uniqID)
+
+	flow, err := b.soxFlow(ctx, cancel, uniqID, req.Format, req.Source)
+	if err != nil {
+		logger.L().Error("Failed create audio pipe", zap.Error(err))
+
+		cancel()
+
+		return false
+	}
+
+	s.setIngest(flow.ingest)
+
+	defer func() {
+		cancel()
+
+		if err := flow.pipe.Wait(); err != nil {
+			logger.L().Error("audio pipe failed", zap.Error(err))
+		}
+	}()
+
+	speech := &speechTimeline{}
+	signals := make(chan Verdict, 1)
+
+	go watchAnalysis(ctx, flow.analyze.Events(), speech, signals)
+
+	resCh, errCh := b.KaldiClient.ProcessAudio(ctx, flow.pipe.StdOut)
+
+	verdict, err := b.decide(
+		ctx, cancel, text.tee(ctx, resCh), errCh, signals, flow.ingest.firstAudio(), policy.DecideWithin,
+	)
+
+	cause, abandoned := s.settle()
+	if abandoned {
+		verdict, err = abandonedVerdict(cause), nil
+		channelUp = false
+	}
+
+	if errors.Is(err, phrase.ErrPhraseNotFound) {
+		logger.L().Info("bot is not finded", zap.Error(err))
+	}
+
+	if err != nil {
+		logger.L().Info("err find stop phrase", zap.Error(err))
+
+		cancel()
+
+		return false
+	}
+
+	if s.isCanceled() {
+		return false
+	}
+
+	if req.Replier != nil {
+		req.Replier.verdict(verdict, text.String())
+	}
+
+	if c.server != nil && !abandoned && (b.VerdictVariables || b.VerdictEvent != "") {
+		b.publishOutcome(c, b.outcome(verdict, text.String(), flow.analyze.Position()))
+	}
+
+	stats := flow.stats(speech)
+
+	b.Metrics.StoreAudioQuality(stats.metricsQuality())
+
+	hungUp := false
+
+	switch verdict.Action {
+	case ActionHangupBot:
+		logger.L().Info("found a bot", zap.Object("verdict", verdict))
+
+		if !b.DialplanDecides && !waitGrace(ctx, policy.HangupGrace) {
+			logger.L().Info("check ended before the hangup", zap.String("call", uniqID))
+
+			break
+		}
+
+		hungUp = b.HangupBot(ctx, c, verdict, stats, policy)
+		channelUp = !hungUp
+	case ActionHangupFax:
+		logger.L().Info("found a fax", zap.Object("verdict", verdict))
 
-	caller, _ := channel.GetVariable("CALLERID(num)")
-	dnID, _ := channel.GetVariable("DNID")
+		if !b.DialplanDecides && !waitGrace(ctx, policy.HangupGrace) {
+			logger.L().Info("check ended before the hangup", zap.String("call", uniqID))
 
+			break
+		}
+
+		hungUp = b.HangupFax(ctx, c, verdict, policy)
+		channelUp = !hungUp
+	case ActionAbandoned:
+		logger.L().Info("check abandoned", zap.String("call", uniqID), zap.Object("cause", cause))
+
+		b.NotifyAbandoned(c, cause, text.String(), flow.analyze.Position(), policy)
+	default:
+		logger.L().Info("Bot not found")
+
+		b.NotifyBotNotFound(c, stats, policy)
+	}
+
+	if b.SaveRecords {
+		b.saveMetadata(c, started, verdict, text.String(), flow.analyze.Position(), stats.Speech)
+	}
+
+	return hungUp
+}
+
+// waitGrace waits the grace before a hangup, false when ctx is done first.
+func waitGrace(ctx context.Context, grace time.Duration) bool {
+	if grace <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// HangupBot ends a call answered by a bot and reports whether it was hung up.
+func (b *BotChecker) HangupBot(ctx context.Context, c call, verdict Verdict, stats CheckStats, policy Policy) bool {
 	b.EventPublisher.Notify(ctx, &checkevents.BotFound{
-		CallID:    uniqID,
-		Dest:      dnID,
-		From:      caller,
-		Phrase:    stopPhrase.Phrase,
-		EventName: checkevents.KeyBotFound,
+		CallID:                 c.ID,
+		Dest:                   c.DNID,
+		From:                   c.Caller,
+		Phrase:                 verdict.PhraseText(),
+		Category:               verdict.Category,
+		Source:                 verdict.Source,
+		Detail:                 verdict.Detail,
+		GreetingMs:             stats.Speech.Greeting.Milliseconds(),
+		SilenceAfterGreetingMs: stats.Speech.SilenceAfterGreeting.Milliseconds(),
+		AudioQuality:           stats.eventQuality(),
+		Policy:                 policy.event(),
+		EventName:              checkevents.KeyBotFound,
 	})
 
-	err := channel.Hangup()
+	if b.DialplanDecides {
+		b.storeVerdict(verdict)
+		logger.L().Info("hangup left to the dialplan", zap.Object("verdict", verdict))
+
+		return false
+	}
+
+	err := c.hangup()
 	if err != nil {
 		logger.L().Error("Unable hangup channel", zap.Error(err))
 
-		return
+		return false
 	}
 
-	b.Metrics.StoreIvrCheckHangup(stopPhrase)
+	b.storeVerdict(verdict)
 
-	logger.L().Info("bot hangup", zap.Object("phrase", stopPhrase))
+	logger.L().Info("bot hangup", zap.Object("verdict", verdict))
+
+	return true
 }
 
-func (b *BotChecker) soxFlow(ctx context.Context, cancel context.CancelFunc, conn net.Conn) (io.Reader, error) {
-	var (
-		err       error
-		outResult io.Reader
-		audioOut  io.Reader
-		audioIn   io.Writer
-	)
+// HangupFax ends a call answered by a fax machine or a modem and reports whether it was hung up.
+func (b *BotChecker) HangupFax(ctx context.Context, c call, verdict Verdict, policy Policy) bool {
+	b.EventPublisher.Notify(ctx, &checkevents.FaxFound{
+		CallID:    c.ID,
+		Dest:      c.DNID,
+		From:      c.Caller,
+		Tone:      verdict.Detail,
+		Policy:    policy.event(),
+		EventName: checkevents.KeyFaxFound,
+	})
+
+	if b.DialplanDecides {
+		b.storeVerdict(verdict)
+		logger.L().Info("hangup left to the dialplan", zap.Object("verdict", verdict))
 
-	audioOut, audioIn, err = os.Pipe()
+		return false
+	}
+
+	err := c.hangup()
 	if err != nil {
-		return nil, err
+		logger.L().Error("Unable hangup channel", zap.Error(err))
+
+		return false
 	}
 
-	if b.SaveRecords {
-		var tmpRaw io.Writer
+	b.storeVerdict(verdict)
 
-		tmpRaw, err = os.CreateTemp("/tmp/botrec", "raw_"+time.Now().Format(time.RFC3339Nano)+"*.pcm")
-		if err != nil {
-			logger.L().Info("error create tmp pcm record", zap.Error(err))
+	logger.L().Info("fax hangup", zap.Object("verdict", verdict))
 
-			return nil, err
-		}
+	return true
+}
 
-		audioOut = io.TeeReader(audioOut, tmpRaw)
-	}
+// NotifyAbandoned publishes the check of a call that hung up before the verdict.
+func (b *BotChecker) NotifyAbandoned(c call, cause hangupCause, text string, listened time.Duration, policy Policy) {
+	// the check context is already done here
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	b.EventPublisher.Notify(ctx, &checkevents.CallAbandoned{
+		CallID:     c.ID,
+		Dest:       c.DNID,
+		From:       c.Caller,
+		Cause:      cause.Code,
+		CauseTxt:   cause.Text,
+		ListenedMs: listened.Milliseconds(),
+		Transcript: text,
+		Policy:     policy.event(),
+		EventName:  checkevents.KeyCallAbandoned,
+	})
 
-	readAudioForkMessages(ctx, cancel, conn, audioIn)
-	logger.L().Info("sox started")
+	b.Metrics.StoreCheckAbandoned(strconv.Itoa(cause.Code))
+}
 
-	pipe, err := audio.NewPipe([]audio.PipeCommand{
-		commands2.NewPcmRawToWav(),
-		commands2.NewPcmWavSilence(),
+// NotifyBotNotFound publishes the result of a check that ended without a stop phrase.
+func (b *BotChecker) NotifyBotNotFound(c call, stats CheckStats, policy Policy) {
+	// the check context is already done here
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	b.EventPublisher.Notify(ctx, &checkevents.BotNotFounded{
+		CallID:                 c.ID,
+		Dest:                   c.DNID,
+		From:                   c.Caller,
+		GreetingMs:             stats.Speech.Greeting.Milliseconds(),
+		SilenceAfterGreetingMs: stats.Speech.SilenceAfterGreeting.Milliseconds(),
+		AudioQuality:           stats.eventQuality(),
+		Policy:                 policy.event(),
+		EventName:              checkevents.KeyBotNotFound,
+	})
+}
+
+func (b *BotChecker) saveMetadata(
+	c call,
+	started time.Time,
+	verdict Verdict,
+	text string,
+	duration time.Duration,
+	speech SpeechStats,
+) {
+	err := b.Recordings.SaveMetadata(recording.Metadata{
+		CallID:                 c.ID,
+		Caller:                 c.Caller,
+		DNID:                   c.DNID,
+		StartedAt:              started,
+		IsBot:                  verdict.IsBot,
+		Action:                 verdict.Action,
+		Source:                 verdict.Source,
+		Category:               verdict.Category,
+		Detail:                 verdict.Detail,
+		Phrase:                 verdict.PhraseText(),
+		Transcript:             text,
+		DurationMs:             duration.Milliseconds(),
+		GreetingMs:             speech.Greeting.Milliseconds(),
+		SilenceAfterGreetingMs: speech.SilenceAfterGreeting.Milliseconds(),
 	})
 	if err != nil {
-		return nil, err
+		logger.L().Error("unable save record metadata", zap.Error(err))
 	}
+}
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				buf := make([]byte, audio.BUFFSIZE)
+// checkFlow is the audio pipeline of one check and the parts measuring it.
+type checkFlow struct {
+	pipe    *audio.Pipe
+	analyze *commands2.Analyze
+	quality *audio.QualityMeter
+	ingest  *ingest
+	format  audio.Format
+}
 
-				_, err = io.ReadFull(audioOut, buf)
-				if err == io.EOF {
-					continue
-				}
+func (f *checkFlow) stats(speech *speechTimeline) CheckStats {
+	return CheckStats{
+		Speech:        speech.stats(f.analyze.Position()),
+		Quality:       f.quality.Stats(),
+		DroppedFrames: f.ingest.droppedFrames(f.format),
+	}
+}
 
-				err = pipe.Write(buf)
+// audioSource feeds in with the call audio until ctx is done and returns the format of the audio.
+// A source learning the format from the stream returns once the first audio arrived or the check ended,
+// the others return the given format at once.
+type audioSource func(ctx context.Context, cancel context.CancelFunc, in io.WriteCloser, format audio.Format) audio.Format
+
+// forkSource reads the audio sent by the AudioFork module over the ws connection.
+func forkSource(conn *forkConn, uniqID string) audioSource {
+	return func(ctx context.Context, cancel context.CancelFunc, in io.WriteCloser, format audio.Format) audio.Format {
+		return readAudioForkMessages(ctx, cancel, conn, in, format, func(payload []byte) {
+			logger.L().Info("AudioFork metadata", zap.String("call", uniqID), zap.ByteString("payload", payload))
+		})
+	}
+}
 
-				if err != nil {
-					logger.L().Error("failed write to pipe", zap.Error(err))
+func (b *BotChecker) soxFlow(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	uniqID string,
+	format audio.Format,
+	source audioSource,
+) (*checkFlow, error) {
+	var err error
 
-					cancel()
+	// frames are taken from the first one on, while the pipe below is being started
+	preroll := audio.NewPreRoll(b.preRollSize(format))
 
-					return
-				}
-			}
-		}
+	go func() {
+		<-ctx.Done()
+
+		preroll.Abort(ctx.Err())
 	}()
 
-	err = pipe.Run(ctx)
-	if err != nil {
-		logger.L().Error("error run sox pipe", zap.Error(err))
+	in := newIngest(preroll)
 
+	format = source(ctx, cancel, in, format)
+	logger.L().Info("sox started", zap.Stringer("format", format))
+
+	wavCommands, err := commands2.NewWavPipeline(b.AudioBackend, b.SampleRate)
+	if err != nil {
 		return nil, err
 	}
 
-	outResult = pipe.StdOut
+	quality := audio.NewQualityMeter()
+	detectors := []audio.Detector{quality}
 
-	return outResult, nil
-}
+	if b.VadEnabled {
+		detectors = append(detectors, audio.NewVAD(audio.VADOptions{SkipSilenceAfter: b.VadSkipSilence}))
+	}
+
+	if b.BeepEnabled {
+		detectors = append(detectors, audio.NewBeepDetector(b.SampleRate, b.Beep))
+	}
+
+	if b.SITEnabled {
+		detectors = append(detectors, audio.NewSITDetector(b.SampleRate, b.SIT))
+	}
+
+	if b.FaxEnabled {
+		detectors = append(detectors, audio.NewFaxDetector(b.SampleRate, b.Fax))
+	}
+
+	analyze := commands2.NewAnalyze(b.SampleRate, detectors...)
 
-func readAudioForkMessages(ctx context.Context, cancel context.CancelFunc, conn net.Conn, inAudio io.Writer) {
 	var (
-		err        error
-		soxStarted bool
-		header     ws.Header
+		pipeCommands []audio.PipeCommand
+		record       *recording.Record
 	)
 
-	startSox := make(chan interface{}, 1)
+	if format != b.inputFormat() || b.SaveRecords {
+		decode := commands2.NewDecode(format, b.SampleRate, b.RemoteChannel)
 
-	go func() {
-		defer func() { _ = conn.Close() }()
+		if b.SaveRecords {
+			record, err = b.Recordings.Create(uniqID, audio.WavFormat{
+				SampleRate:    format.SampleRate,
+				Channels:      format.Channels,
+				BitsPerSample: 16,
+			})
+			if err != nil {
+				logger.L().Error("error create call record", zap.Error(err))
+
+				return nil, err
+			}
 
-		for {
-			select {
-			case <-ctx.Done():
-				inAudio = io.Discard
+			decode.Record(record)
+		}
+
+		pipeCommands = append(pipeCommands, decode)
+	}
 
-				return
-			default:
-				header, err = ws.ReadHeader(conn)
-				if err != nil {
-					logger.L().Error("unable read ws header", zap.Error(err))
-					cancel()
+	pipeCommands = append(pipeCommands, analyze)
 
-					return
-				}
+	// the record of a check that has not started would stay truncated
+	discard := func() {
+		if record == nil {
+			return
+		}
+
+		if err := record.Discard(); err != nil {
+			logger.L().Error("unable discard call record", zap.Error(err))
+		}
+	}
 
-				if !soxStarted {
-					startSox <- struct{}{}
+	pipe, err := audio.NewPipe(append(pipeCommands, wavCommands...))
+	if err != nil {
+		discard()
 
-					soxStarted = true
-				}
+		return nil, err
+	}
 
-				payload := make([]byte, header.Length)
+	err = pipe.Start(ctx)
+	if err != nil {
+		logger.L().Error("error run sox pipe", zap.Error(err))
 
-				_, err = io.ReadFull(conn, payload)
-				if err != nil {
-					logger.L().Error("unable read ws message", zap.Error(err))
-					cancel()
+		_ = pipe.Wait()
 
-					return
-				}
+		discard()
 
-				_, _ = inAudio.Write(payload)
+		return nil, err
+	}
 
-				if header.OpCode == ws.OpClose {
-					logger.L().Debug("OpClose AudioFork")
-					cancel()
+	go func() {
+		_, err := io.Copy(pipe.StdIn, preroll)
+		if err != nil && ctx.Err() == nil {
+			logger.L().Error("failed write to pipe", zap.Error(err))
 
-					return
-				}
-			}
+			cancel()
 		}
+
+		_ = pipe.Close()
 	}()
 
-	<-startSox
+	return &checkFlow{
+		pipe:    pipe,
+		analyze: analyze,
+		quality: quality,
+		ingest:  in,
+		format:  format,
+	}, nil
 }
@@ -0,0 +1,339 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/domain/phrase"
+	"github.com/Arten331/bot-checker/internal/domain/phrase/memdb"
+	"github.com/Arten331/bot-checker/internal/events"
+	"github.com/Arten331/bot-checker/internal/models"
+	ariclient "github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/Arten331/bot-checker/pkg/ari/aritest"
+	"github.com/Arten331/bot-checker/pkg/audio/commands"
+	"github.com/Arten331/observability/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedRecognizer hears text once some audio has arrived.
+type scriptedRecognizer struct {
+	text string
+}
+
+func (r scriptedRecognizer) ProcessAudio(ctx context.Context, reader io.Reader) (chan models.KaldiMessage, chan error) {
+	resCh := make(chan models.KaldiMessage)
+	errCh := make(chan error, 1)
+
+	go func() {
+		if _, err := io.ReadFull(reader, make([]byte, 3200)); err != nil {
+			return
+		}
+
+		select {
+		case resCh <- models.KaldiMessage{Text: []byte(r.text), IsFinal: true}:
+		case <-ctx.Done():
+			return
+		}
+
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+
+	return resCh, errCh
+}
+
+// deafRecognizer never hears anything.
+type deafRecognizer struct{}
+
+func (deafRecognizer) ProcessAudio(_ context.Context, reader io.Reader) (chan models.KaldiMessage, chan error) {
+	go func() { _, _ = io.Copy(io.Discard, reader) }()
+
+	return make(chan models.KaldiMessage), make(chan error, 1)
+}
+
+func newTestChecker(t *testing.T, ariServer *aritest.Server, o Options) *BotChecker {
+	t.Helper()
+
+	repo, err := memdb.NewPhraseMemDBRepository()
+	require.NoError(t, err)
+	require.NoError(t, repo.Load([]*phrase.StopPhrase{phrase.New("оставьте сообщение после сигнала", "voicemail")}))
+
+	ms := metrics.New()
+
+	o.MetricService = &ms
+	o.StopPhrasesRepository = &repo
+	client := ariclient.New(ariServer.Options())
+	o.AriClient = client
+	o.Channels = client
+
+	if o.MediaHost != "" {
+		o.MediaChannels = client
+	}
+	o.AudioBackend = commands.BackendNative
+	o.EventPublisher = events.NewEventPublisher()
+
+	b, err := New(&o)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestCheckBotHandlerHangsUpBot(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("call-1", map[string]string{"CALLERID(num)": "79990001122", "DNID": "88005003333"})
+
+	b := newTestChecker(t, ariServer, Options{
+		KaldiClient: scriptedRecognizer{text: "оставьте сообщение после сигнала"},
+	})
+
+	verdict := streamTone(t, b, "/bot-check/call-1")
+	require.True(t, verdict.IsBot)
+	require.Equal(t, "voicemail", verdict.Category)
+
+	require.Eventually(t, func() bool {
+		ch, _ := ariServer.Channel("call-1")
+
+		return ch.HungUp
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, []string{
+		"POST /applications/bot_checker/subscription",
+		"GET /channels/call-1/variable",
+		"GET /channels/call-1/variable",
+		"DELETE /channels/call-1",
+	}, ariServer.Calls())
+}
+
+func TestCheckBotHandlerRoutesByServer(t *testing.T) {
+	main := aritest.NewServer()
+	defer main.Close()
+
+	box2 := aritest.NewServer()
+	defer box2.Close()
+
+	box2.AddChannel("call-1", nil)
+
+	box2Client := ariclient.New(box2.Options())
+
+	b := newTestChecker(t, main, Options{
+		KaldiClient: scriptedRecognizer{text: "оставьте сообщение после сигнала"},
+		AriServers:  []AriServer{{Name: "box2", Client: box2Client}},
+	})
+
+	verdict := streamTone(t, b, "/bot-check/call-1?server=box2")
+	require.True(t, verdict.IsBot)
+
+	require.Eventually(t, func() bool {
+		ch, _ := box2.Channel("call-1")
+
+		return ch.HungUp
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Empty(t, main.Calls())
+}
+
+func TestCheckBotHandlerUnknownServer(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	b := newTestChecker(t, ariServer, Options{KaldiClient: scriptedRecognizer{}})
+
+	rec := httptest.NewRecorder()
+	b.CheckBotHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bot-check/call-1?server=box9", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCheckAbandonedOnHangup(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("call-1", nil)
+
+	b := newTestChecker(t, ariServer, Options{KaldiClient: deafRecognizer{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go b.AriClient.(*ariclient.Client).Run(ctx, cancel)
+	go b.watchHangups(ctx, b.servers[DefaultServer])
+
+	require.Eventually(t, func() bool { return b.Ready() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	go func() {
+		for ctx.Err() == nil {
+			if ch, _ := ariServer.Channel("call-1"); ch.Subscribed {
+				ariServer.Hangup("call-1", 17, "User busy")
+
+				return
+			}
+
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	verdict := streamTone(t, b, "/bot-check/call-1")
+	require.False(t, verdict.IsBot)
+	require.Equal(t, ActionAbandoned, verdict.Action)
+	require.Equal(t, "User busy", verdict.Detail)
+
+	require.NotContains(t, ariServer.Calls(), "DELETE /channels/call-1")
+}
+
+func TestReadyFollowsAri(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	b := newTestChecker(t, ariServer, Options{KaldiClient: scriptedRecognizer{}})
+	require.ErrorIs(t, b.Ready(), ErrAriDisconnected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go b.AriClient.(*ariclient.Client).Run(ctx, cancel)
+
+	require.Eventually(t, func() bool { return b.Ready() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	ariServer.SetDown(true)
+	require.Eventually(t, func() bool { return b.Ready() != nil }, 5*time.Second, 10*time.Millisecond)
+
+	ariServer.SetDown(false)
+	require.Eventually(t, func() bool { return b.Ready() == nil }, 5*time.Second, 10*time.Millisecond)
+}
+
+// streamTone sends a tone to the check at path until the verdict comes back.
+func streamTone(t *testing.T, b *BotChecker, path string) forkVerdict {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.Handle("/bot-check/{uniqID}", b.CheckBotHandler())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, _, _, err := ws.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+path)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	// a tone, silence is stripped before the recognizer
+	frame := make([]byte, 320)
+	for i := 0; i < len(frame)/2; i++ {
+		binary.LittleEndian.PutUint16(frame[2*i:], uint16(int16(8000*math.Sin(2*math.Pi*440*float64(i)/8000))))
+	}
+
+	go func() {
+		for ctx.Err() == nil {
+			if err := wsutil.WriteClientBinary(conn, frame); err != nil {
+				return
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	payload, err := wsutil.ReadServerText(conn)
+	require.NoError(t, err)
+
+	var verdict forkVerdict
+
+	require.NoError(t, json.Unmarshal(payload, &verdict))
+
+	return verdict
+}
+
+func TestReadyIgnoresOtherServerDown(t *testing.T) {
+	main := aritest.NewServer()
+	defer main.Close()
+
+	box2 := aritest.NewServer()
+	defer box2.Close()
+
+	box2Client := ariclient.New(box2.Options())
+
+	b := newTestChecker(t, main, Options{
+		KaldiClient: scriptedRecognizer{},
+		AriServers:  []AriServer{{Name: "box2", Client: box2Client}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go b.AriClient.(*ariclient.Client).Run(ctx, cancel)
+	go box2Client.Run(ctx, cancel)
+
+	require.Eventually(t, func() bool { return b.Ready() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	box2.SetDown(true)
+	require.Eventually(t, func() bool { return !box2Client.Connected() }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, b.Ready())
+
+	// the default asterisk is required
+	box2.SetDown(false)
+	main.SetDown(true)
+	require.Eventually(t, func() bool { return b.Ready() != nil }, 5*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, b.Ready(), ErrAriDisconnected)
+}
+
+func TestHangupGraceCanceled(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("call-1", nil)
+
+	b := newTestChecker(t, ariServer, mediaOptions(Options{
+		KaldiClient: scriptedRecognizer{text: "оставьте сообщение после сигнала"},
+		Policy:      Policy{MaxListen: 5 * time.Second, HangupGrace: time.Hour},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go b.AriClient.(*ariclient.Client).Run(ctx, cancel)
+
+	resp := postMediaCheck(t, b, "/bot-check/call-1/ari")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sendRTP(ctx, t, ariServer, nil)
+
+	// the bot is heard, the check waits the grace
+	require.Eventually(t, func() bool {
+		s, ok := b.sessions.get(DefaultServer, "call-1")
+
+		return ok && s.info().Transcript != ""
+	}, 5*time.Second, 10*time.Millisecond)
+
+	router := chi.NewRouter()
+	router.Delete("/api/sessions/{id}", b.CancelSessionHandler())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/sessions/call-1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Eventually(t, func() bool {
+		_, ok := b.sessions.get(DefaultServer, "call-1")
+
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NotContains(t, ariServer.Calls(), "DELETE /channels/call-1")
+
+	ch, _ := ariServer.Channel("call-1")
+	require.False(t, ch.HungUp)
+}
@@ -0,0 +1,328 @@
+package botchecker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/Arten331/bot-checker/internal/httpservice/httpwriter"
+	ariclient "github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/Arten331/bot-checker/pkg/rtp"
+	"github.com/Arten331/observability/logger"
+	"github.com/CyCoreSystems/ari"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// maxPacket is the largest RTP packet read, asterisk keeps them within the MTU.
+const maxPacket = 1500
+
+// maxConcealed bounds the lost packets replaced by silence, 5 s of 20 ms packets.
+const maxConcealed = 250
+
+// suffixes of the channel ids created for the media of a call, they enter the application too.
+const (
+	snoopSuffix = "-snoop"
+	mediaSuffix = "-media"
+)
+
+// MediaChannels creates the channels streaming call audio to the checker.
+type MediaChannels interface {
+	ExternalMedia(ctx context.Context, o ariclient.ExternalMediaOptions) (*ari.ChannelHandle, error)
+}
+
+// mediaFormats are the asterisk codecs accepted from an externalMedia channel.
+var mediaFormats = map[string]audio.Format{
+	"ulaw":   {Codec: audio.CodecULaw, SampleRate: 8000, Channels: 1},
+	"alaw":   {Codec: audio.CodecALaw, SampleRate: 8000, Channels: 1},
+	"slin":   {Codec: audio.CodecSlin, SampleRate: 8000, Channels: 1},
+	"slin16": {Codec: audio.CodecSlin, SampleRate: 16000, Channels: 1},
+}
+
+// CheckMediaHandler starts a check of the call audio pulled over ARI: a snoop on the channel
+// is bridged with an externalMedia channel sending RTP to the checker.
+// The check runs after the response.
+func (b *BotChecker) CheckMediaHandler() http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rw := httpwriter.NewJSONResponseWriter()
+
+		server, err := b.serverFromQuery(r.URL.Query())
+		if err != nil {
+			rw.WriteError(w, err, http.StatusBadRequest)
+
+			return
+		}
+
+		if !b.mediaConfigured(server) {
+			rw.WriteError(w, errors.New("ARI media is not configured"), http.StatusServiceUnavailable)
+
+			return
+		}
+
+		policy, err := b.Policy.withQuery(r.URL.Query())
+		if err != nil {
+			rw.WriteError(w, err, http.StatusBadRequest)
+
+			return
+		}
+
+		uniqID := chi.URLParam(r, "uniqID")
+
+		// refused before the media is created, the running check keeps the call
+		if _, ok := b.sessions.get(server.name(), uniqID); ok {
+			logger.L().Warn("bot check rejected", zap.String("call", uniqID), zap.Error(ErrSessionExists))
+
+			rw.WriteError(w, ErrSessionExists, http.StatusConflict)
+
+			return
+		}
+
+		release, err := b.admission.acquire(r.Context(), b.KaldiBackend)
+		if err != nil {
+			logger.L().Warn("bot check rejected", zap.String("call", uniqID), zap.Error(err))
+
+			rw.WriteError(w, err, http.StatusServiceUnavailable)
+
+			return
+		}
+
+		b.Metrics.StoreIvrCheckStart()
+
+		// the check outlives the request
+		ctx, cancel := context.WithTimeout(context.Background(), policy.MaxListen)
+
+		session, err := b.startMedia(ctx, server, uniqID)
+		if err != nil {
+			logger.L().Error("unable start ARI media", zap.String("call", uniqID), zap.Error(err))
+
+			cancel()
+			release()
+			rw.WriteError(w, err, http.StatusBadGateway)
+
+			return
+		}
+
+		go func() {
+			defer release()
+			defer cancel()
+			defer session.close()
+
+			b.runCheck(ctx, cancel, b.mediaRequest(server, uniqID, r.RemoteAddr, policy, session))
+		}()
+
+		rw.WriteSuccess(w, "check started", nil)
+	}
+
+	return fn
+}
+
+// mediaRequest is the check of the audio received by session.
+func (b *BotChecker) mediaRequest(
+	server *AriServer,
+	uniqID, remote string,
+	policy Policy,
+	session *mediaSession,
+) checkRequest {
+	format := mediaFormats[b.MediaFormat]
+
+	return checkRequest{
+		ID:        uniqID,
+		Transport: TransportARI,
+		Remote:    remote,
+		Format:    format,
+		Policy:    policy,
+		Source:    rtpSource(session.conn, format.Codec == audio.CodecSlin),
+		Server:    server,
+	}
+}
+
+// mediaConfigured tells whether the call audio can be pulled from server over ARI.
+func (b *BotChecker) mediaConfigured(server *AriServer) bool {
+	return server != nil && server.MediaChannels != nil && b.MediaHost != ""
+}
+
+// mediaSession is the ARI side of a check, torn down with close.
+type mediaSession struct {
+	conn   *net.UDPConn
+	snoop  *ari.ChannelHandle
+	media  *ari.ChannelHandle
+	bridge *ari.BridgeHandle
+}
+
+func (b *BotChecker) startMedia(ctx context.Context, server *AriServer, uniqID string) (_ *mediaSession, err error) {
+	s := &mediaSession{}
+
+	defer func() {
+		if err != nil {
+			s.close()
+		}
+	}()
+
+	s.conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(b.MediaListen)})
+	if err != nil {
+		return nil, err
+	}
+
+	app := server.Client.ApplicationName()
+	port := s.conn.LocalAddr().(*net.UDPAddr).Port
+
+	// the callee side of the call only
+	snoop, err := server.channel(uniqID).Snoop(uniqID+snoopSuffix, &ari.SnoopOptions{
+		App: app,
+		Spy: ari.DirectionIn,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snoop: %w", err)
+	}
+
+	s.snoop = snoop
+
+	media, err := server.MediaChannels.ExternalMedia(ctx, ariclient.ExternalMediaOptions{
+		ChannelID:    uniqID + mediaSuffix,
+		App:          app,
+		ExternalHost: net.JoinHostPort(b.MediaHost, strconv.Itoa(port)),
+		Format:       b.MediaFormat,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.media = media
+
+	bridge, err := server.Client.Bridge().Create(ari.NewKey(ari.BridgeKey, uniqID+"-bridge"), "mixing", "bot-check-"+uniqID)
+	if err != nil {
+		return nil, fmt.Errorf("bridge: %w", err)
+	}
+
+	s.bridge = bridge
+
+	for _, channel := range []*ari.ChannelHandle{s.snoop, s.media} {
+		err = s.bridge.AddChannel(channel.ID())
+		if err != nil {
+			return nil, fmt.Errorf("bridge: %w", err)
+		}
+	}
+
+	logger.L().Info("ARI media started", zap.String("call", uniqID), zap.String("server", server.Name), zap.Int("port", port))
+
+	return s, nil
+}
+
+// close releases what was created, the channels may be gone with the checked call already.
+func (s *mediaSession) close() {
+	if s.bridge != nil {
+		if err := s.bridge.Delete(); err != nil {
+			logger.L().Debug("unable delete media bridge", zap.Error(err))
+		}
+	}
+
+	for _, channel := range []*ari.ChannelHandle{s.media, s.snoop} {
+		if channel == nil {
+			continue
+		}
+
+		if err := channel.Hangup(); err != nil {
+			logger.L().Debug("unable hangup media channel", zap.String("channel", channel.ID()), zap.Error(err))
+		}
+	}
+
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+}
+
+// rtpSource depacketizes the RTP sent to conn, slin payloads arrive in network byte order.
+// Lost packets are replaced by silence to keep the timeline of the call.
+func rtpSource(conn *net.UDPConn, swap bool) audioSource {
+	return func(ctx context.Context, cancel context.CancelFunc, in io.WriteCloser, format audio.Format) audio.Format {
+		go func() {
+			<-ctx.Done()
+
+			_ = conn.Close()
+		}()
+
+		go func() {
+			defer func() { _ = in.Close() }()
+			defer cancel()
+
+			var (
+				buf      = make([]byte, maxPacket)
+				ssrc     uint32
+				seq      uint16
+				received int
+				lost     int
+			)
+
+			defer func() {
+				logger.L().Debug("RTP stream ended", zap.Int("received", received), zap.Int("lost", lost))
+			}()
+
+			for {
+				n, err := conn.Read(buf)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.L().Error("unable read RTP", zap.Error(err))
+					}
+
+					return
+				}
+
+				packet, err := rtp.Parse(buf[:n])
+				if err != nil {
+					logger.L().Debug("invalid RTP packet", zap.Error(err))
+
+					continue
+				}
+
+				gap := 0
+
+				switch {
+				case received == 0:
+					ssrc = packet.SSRC
+				case packet.SSRC != ssrc:
+					continue
+				case rtp.Lost(seq, packet.SequenceNumber) == 0 && packet.SequenceNumber-seq != 1:
+					// late or duplicated, its audio was covered already
+					continue
+				default:
+					gap = rtp.Lost(seq, packet.SequenceNumber)
+					lost += gap
+				}
+
+				seq = packet.SequenceNumber
+				received++
+
+				if swap {
+					swapBytes(packet.Payload)
+				}
+
+				if gap > 0 {
+					if gap > maxConcealed {
+						gap = maxConcealed
+					}
+
+					if _, err = in.Write(format.Silence(gap * len(packet.Payload))); err != nil {
+						return
+					}
+				}
+
+				if _, err = in.Write(packet.Payload); err != nil {
+					return
+				}
+			}
+		}()
+
+		return format
+	}
+}
+
+func swapBytes(b []byte) {
+	for i := 0; i+1 < len(b); i += 2 {
+		b[i], b[i+1] = b[i+1], b[i]
+	}
+}
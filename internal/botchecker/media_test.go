@@ -0,0 +1,175 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ariclient "github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/Arten331/bot-checker/pkg/ari/aritest"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// rtpPacket builds a packet of the stream 0x1234.
+func rtpPacket(seq uint16, payload []byte) []byte {
+	packet := make([]byte, 12, 12+len(payload))
+	packet[0] = 2 << 6
+	binary.BigEndian.PutUint16(packet[2:], seq)
+	binary.BigEndian.PutUint32(packet[4:], uint32(seq)*160)
+	binary.BigEndian.PutUint32(packet[8:], 0x1234)
+
+	return append(packet, payload...)
+}
+
+func TestRTPSourceConcealsLoss(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	in := &audioBuffer{closed: make(chan struct{})}
+	format := audio.Format{Codec: audio.CodecULaw, SampleRate: 8000, Channels: 1}
+
+	rtpSource(conn, false)(ctx, cancel, in, format)
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	defer func() { _ = client.Close() }()
+
+	voice := bytes.Repeat([]byte{0x10}, 160)
+
+	// 2 and 3 are lost, 1 comes late again
+	for _, seq := range []uint16{1, 4, 1, 5} {
+		_, err = client.Write(rtpPacket(seq, voice))
+		require.NoError(t, err)
+	}
+
+	want := append(append([]byte(nil), voice...), format.Silence(2*160)...)
+	want = append(append(want, voice...), voice...)
+
+	require.Eventually(t, func() bool { return len(in.Bytes()) == len(want) }, time.Second, 5*time.Millisecond)
+	require.Equal(t, want, in.Bytes())
+
+	cancel()
+	<-in.closed
+}
+
+func TestCheckMediaHandler(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("call-1", nil)
+
+	b := newTestChecker(t, ariServer, mediaOptions(Options{
+		KaldiClient: scriptedRecognizer{text: "оставьте сообщение после сигнала"},
+		Policy:      Policy{MaxListen: 5 * time.Second},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go b.AriClient.(*ariclient.Client).Run(ctx, cancel)
+
+	resp := postMediaCheck(t, b, "/bot-check/call-1/ari")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sendRTP(ctx, t, ariServer, nil)
+
+	// the bot is hung up, then the media is torn down
+	require.Eventually(t, func() bool {
+		media, _ := ariServer.Channel("call-1" + mediaSuffix)
+		snoop, _ := ariServer.Channel("call-1" + snoopSuffix)
+
+		return media.HungUp && snoop.HungUp
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ch, _ := ariServer.Channel("call-1")
+	require.True(t, ch.HungUp)
+
+	var calls []string
+
+	for _, c := range ariServer.Calls() {
+		// variables and subscriptions are not part of the media
+		if !strings.HasPrefix(c, "GET ") && !strings.Contains(c, "/applications/") {
+			calls = append(calls, c)
+		}
+	}
+
+	require.Equal(t, []string{
+		"POST /channels/call-1/snoop/call-1-snoop",
+		"POST /channels/externalMedia",
+		"POST /bridges/call-1-bridge",
+		"POST /bridges/call-1-bridge/addChannel",
+		"POST /bridges/call-1-bridge/addChannel",
+		"DELETE /channels/call-1",
+		"DELETE /bridges/call-1-bridge",
+		"DELETE /channels/call-1-media",
+		"DELETE /channels/call-1-snoop",
+	}, calls)
+}
+
+func TestCheckMediaHandlerStartFails(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	b := newTestChecker(t, ariServer, mediaOptions(Options{KaldiClient: deafRecognizer{}}))
+
+	// there is no such call to snoop on
+	resp := postMediaCheck(t, b, "/bot-check/call-1/ari")
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	require.Equal(t, []string{"POST /channels/call-1/snoop/call-1-snoop"}, ariServer.Calls())
+	require.Empty(t, b.sessions.list())
+}
+
+func TestMediaSessionClose(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("call-1", nil)
+
+	b := newTestChecker(t, ariServer, mediaOptions(Options{KaldiClient: deafRecognizer{}}))
+
+	s, err := b.startMedia(context.Background(), b.servers[DefaultServer], "call-1")
+	require.NoError(t, err)
+
+	// the checked call took the snoop with it
+	ariServer.Hangup("call-1"+snoopSuffix, 16, "")
+	s.close()
+
+	media, _ := ariServer.Channel("call-1" + mediaSuffix)
+	require.True(t, media.HungUp)
+	require.Contains(t, ariServer.Calls(), "DELETE /bridges/call-1-bridge")
+
+	_, err = s.conn.Write([]byte{0})
+	require.Error(t, err)
+}
+
+// postMediaCheck requests a check of the call audio pulled over ARI.
+func postMediaCheck(t *testing.T, b *BotChecker, path string) *http.Response {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.Post("/bot-check/{uniqID}/ari", b.CheckMediaHandler())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+path, "application/json", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	return resp
+}
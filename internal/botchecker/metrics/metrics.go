@@ -20,6 +20,19 @@ type MetricCollectors struct {
 	waitForNoiseHangup *prometheus.CounterVec
 	ivrCheckStart      *prometheus.CounterVec
 	ivrCheckHangup     *prometheus.CounterVec
+	voicemailBeep      *prometheus.CounterVec
+	disconnected       *prometheus.CounterVec
+	fax                *prometheus.CounterVec
+	audioRMS           *prometheus.HistogramVec
+	audioPeak          *prometheus.HistogramVec
+	audioClipping      *prometheus.HistogramVec
+	audioSilence       *prometheus.HistogramVec
+	audioSpeech        *prometheus.HistogramVec
+	audioDroppedFrames *prometheus.HistogramVec
+	checksActive       *prometheus.GaugeVec
+	checksQueued       *prometheus.GaugeVec
+	checksRejected     *prometheus.CounterVec
+	checksAbandoned    *prometheus.CounterVec
 }
 
 type WaitForNoise struct {
@@ -30,6 +43,16 @@ type WaitForNoise struct {
 	Campaign string
 }
 
+// AudioQuality is the audio of one checked call, levels are in dBFS.
+type AudioQuality struct {
+	RMSDB         float64
+	PeakDB        float64
+	ClippingRatio float64
+	SilenceRatio  float64
+	SpeechSeconds float64
+	DroppedFrames int
+}
+
 func (m *Metrics) Collectors() MetricCollectors {
 	return m.collectors
 }
@@ -59,15 +82,172 @@ func (m *Metrics) Register() {
 		[]string{"phrase", "group"},
 	)
 
+	voicemailBeep := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ivr_check_voicemail_beep",
+			Help: "Bot checks decided by the voicemail beep",
+		},
+		[]string{"group"},
+	)
+
+	disconnected := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ivr_check_disconnected",
+			Help: "Bot checks decided by the special information tone",
+		},
+		[]string{"group", "variant"},
+	)
+
+	fax := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ivr_check_fax",
+			Help: "Calls answered by a fax machine or a modem",
+		},
+		[]string{"group", "tone"},
+	)
+
+	levelBuckets := prometheus.LinearBuckets(-90, 6, 16)
+	ratioBuckets := []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 0.75, 0.9, 1}
+
+	audioRMS := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ivr_check_audio_rms_db",
+			Help:    "Average level of checked call audio, dBFS",
+			Buckets: levelBuckets,
+		},
+		[]string{"group"},
+	)
+
+	audioPeak := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ivr_check_audio_peak_db",
+			Help:    "Peak level of checked call audio, dBFS",
+			Buckets: levelBuckets,
+		},
+		[]string{"group"},
+	)
+
+	audioClipping := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ivr_check_audio_clipping_ratio",
+			Help:    "Share of clipped samples in checked call audio",
+			Buckets: ratioBuckets,
+		},
+		[]string{"group"},
+	)
+
+	audioSilence := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ivr_check_audio_silence_ratio",
+			Help:    "Share of silent frames in checked call audio",
+			Buckets: ratioBuckets,
+		},
+		[]string{"group"},
+	)
+
+	audioSpeech := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ivr_check_audio_speech_seconds",
+			Help:    "Speech in checked call audio",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 40, 60, 120},
+		},
+		[]string{"group"},
+	)
+
+	audioDroppedFrames := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ivr_check_audio_dropped_frames",
+			Help:    "Frames of checked call audio lost or late on the way",
+			Buckets: []float64{0, 1, 5, 10, 50, 100, 500},
+		},
+		[]string{"group"},
+	)
+
+	checksActive := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ivr_check_active",
+			Help: "Bot checks running now",
+		},
+		[]string{"group", "backend"},
+	)
+
+	checksQueued := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ivr_check_queued",
+			Help: "Bot checks waiting for a slot",
+		},
+		[]string{"group"},
+	)
+
+	checksRejected := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ivr_check_rejected",
+			Help: "Bot checks rejected over the concurrency limits",
+		},
+		[]string{"group", "reason"},
+	)
+
+	checksAbandoned := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ivr_check_abandoned",
+			Help: "Bot checks ended by the call hanging up first",
+		},
+		[]string{"group", "cause"},
+	)
+
 	m.collectors = MetricCollectors{
 		waitForNoiseHangup: waitForNoiseHangup,
 		ivrCheckStart:      ivrCheckStart,
 		ivrCheckHangup:     ivrCheckHangup,
+		voicemailBeep:      voicemailBeep,
+		disconnected:       disconnected,
+		fax:                fax,
+		audioRMS:           audioRMS,
+		audioPeak:          audioPeak,
+		audioClipping:      audioClipping,
+		audioSilence:       audioSilence,
+		audioSpeech:        audioSpeech,
+		audioDroppedFrames: audioDroppedFrames,
+		checksActive:       checksActive,
+		checksQueued:       checksQueued,
+		checksRejected:     checksRejected,
+		checksAbandoned:    checksAbandoned,
 	}
 
 	_ = m.Service.Register(waitForNoiseHangup)
 	_ = m.Service.Register(ivrCheckStart)
 	_ = m.Service.Register(ivrCheckHangup)
+	_ = m.Service.Register(voicemailBeep)
+	_ = m.Service.Register(disconnected)
+	_ = m.Service.Register(fax)
+	_ = m.Service.Register(audioRMS)
+	_ = m.Service.Register(audioPeak)
+	_ = m.Service.Register(audioClipping)
+	_ = m.Service.Register(audioSilence)
+	_ = m.Service.Register(audioSpeech)
+	_ = m.Service.Register(audioDroppedFrames)
+	_ = m.Service.Register(checksActive)
+	_ = m.Service.Register(checksQueued)
+	_ = m.Service.Register(checksRejected)
+	_ = m.Service.Register(checksAbandoned)
+}
+
+// RegisterAriConnected exports the state of the ARI connection to server, read on every scrape.
+func (m *Metrics) RegisterAriConnected(server string, connected func() bool) {
+	_ = m.Service.Register(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name:        "ari_connected",
+			Help:        "Whether asterisk answers over ARI",
+			ConstLabels: prometheus.Labels{"group": label, "server": server},
+		},
+		func() float64 {
+			if connected() {
+				return 1
+			}
+
+			return 0
+		},
+	))
 }
 
 func (m *Metrics) StoreNoiseHangup(r *WaitForNoise) {
@@ -85,6 +265,49 @@ func (m *Metrics) StoreIvrCheckHangup(p *phrase.StopPhrase) {
 	logger.L().Debug("stored ivr check hangup")
 }
 
+func (m *Metrics) StoreVoicemailBeep() {
+	m.collectors.voicemailBeep.WithLabelValues(label).Inc()
+	logger.L().Debug("stored voicemail beep")
+}
+
+func (m *Metrics) StoreDisconnected(variant string) {
+	m.collectors.disconnected.WithLabelValues(label, variant).Inc()
+	logger.L().Debug("stored disconnected", zap.String("variant", variant))
+}
+
+func (m *Metrics) StoreFax(tone string) {
+	m.collectors.fax.WithLabelValues(label, tone).Inc()
+	logger.L().Debug("stored fax", zap.String("tone", tone))
+}
+
+func (m *Metrics) StoreAudioQuality(q AudioQuality) {
+	m.collectors.audioRMS.WithLabelValues(label).Observe(q.RMSDB)
+	m.collectors.audioPeak.WithLabelValues(label).Observe(q.PeakDB)
+	m.collectors.audioClipping.WithLabelValues(label).Observe(q.ClippingRatio)
+	m.collectors.audioSilence.WithLabelValues(label).Observe(q.SilenceRatio)
+	m.collectors.audioSpeech.WithLabelValues(label).Observe(q.SpeechSeconds)
+	m.collectors.audioDroppedFrames.WithLabelValues(label).Observe(float64(q.DroppedFrames))
+	logger.L().Debug("stored audio quality")
+}
+
+func (m *Metrics) StoreCheckActive(backend string, delta int) {
+	m.collectors.checksActive.WithLabelValues(label, backend).Add(float64(delta))
+}
+
+func (m *Metrics) StoreCheckQueued(delta int) {
+	m.collectors.checksQueued.WithLabelValues(label).Add(float64(delta))
+}
+
+func (m *Metrics) StoreCheckRejected(reason string) {
+	m.collectors.checksRejected.WithLabelValues(label, reason).Inc()
+	logger.L().Debug("stored check rejected", zap.String("reason", reason))
+}
+
+func (m *Metrics) StoreCheckAbandoned(cause string) {
+	m.collectors.checksAbandoned.WithLabelValues(label, cause).Inc()
+	logger.L().Debug("stored check abandoned", zap.String("cause", cause))
+}
+
 func (m *Metrics) ResetNoiseHangup() {
 	m.collectors.waitForNoiseHangup.Reset()
 }
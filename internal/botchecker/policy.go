@@ -0,0 +1,80 @@
+package botchecker
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	checkevents "github.com/Arten331/bot-checker/internal/events/bot_checker"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultMaxListen = 120 * time.Second
+
+// Policy bounds a check in time, a campaign may override it in the query of the check.
+type Policy struct {
+	MaxListen    time.Duration // of the whole check
+	DecideWithin time.Duration // from the first audio, the check ends undecided after it; 0 for the whole check
+	HangupGrace  time.Duration // between the verdict and the hangup
+}
+
+// withQuery applies max_listen, decide_within and hangup_grace parameters, Go durations like 30s.
+// max_listen and hangup_grace are capped at the configured ones, a client may not hold the check longer.
+func (p Policy) withQuery(query url.Values) (Policy, error) {
+	maxListen, hangupGrace := p.MaxListen, p.HangupGrace
+
+	for key, dst := range map[string]*time.Duration{
+		"max_listen":    &p.MaxListen,
+		"decide_within": &p.DecideWithin,
+		"hangup_grace":  &p.HangupGrace,
+	} {
+		if v := query.Get(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return p, fmt.Errorf("%s: %w", key, err)
+			}
+
+			*dst = d
+		}
+	}
+
+	if p.MaxListen > maxListen {
+		p.MaxListen = maxListen
+	}
+
+	if p.HangupGrace > hangupGrace {
+		p.HangupGrace = hangupGrace
+	}
+
+	return p, p.Validate()
+}
+
+func (p Policy) Validate() error {
+	switch {
+	case p.MaxListen <= 0:
+		return errors.New("max listen must be positive")
+	case p.DecideWithin < 0:
+		return errors.New("decide within must not be negative")
+	case p.HangupGrace < 0:
+		return errors.New("hangup grace must not be negative")
+	}
+
+	return nil
+}
+
+func (p Policy) event() checkevents.Policy {
+	return checkevents.Policy{
+		MaxListenMs:    p.MaxListen.Milliseconds(),
+		DecideWithinMs: p.DecideWithin.Milliseconds(),
+		HangupGraceMs:  p.HangupGrace.Milliseconds(),
+	}
+}
+
+func (p Policy) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddDuration("max_listen", p.MaxListen)
+	encoder.AddDuration("decide_within", p.DecideWithin)
+	encoder.AddDuration("hangup_grace", p.HangupGrace)
+
+	return nil
+}
@@ -0,0 +1,207 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/domain/phrase/memdb"
+	"github.com/Arten331/bot-checker/internal/events"
+	checkevents "github.com/Arten331/bot-checker/internal/events/bot_checker"
+	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/observability/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder keeps the events published to it.
+type eventRecorder struct {
+	events []events.Event
+}
+
+func (r *eventRecorder) Notify(_ context.Context, event events.Event) {
+	r.events = append(r.events, event)
+}
+
+// publishedPolicy decodes the policy of an event as sent to kafka.
+func publishedPolicy(t *testing.T, event events.Event) checkevents.Policy {
+	t.Helper()
+
+	msg, err := event.(checkevents.Event).KafkaMessage()
+	require.NoError(t, err)
+
+	var click checkevents.ClickKafkaMessage
+
+	require.NoError(t, json.Unmarshal(msg.Value, &click))
+
+	var data struct {
+		Policy checkevents.Policy `json:"policy"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(click.Data), &data))
+
+	return data.Policy
+}
+
+// newOfflineChecker builds a checker without ARI, the recognizer never hears anything unless given.
+func newOfflineChecker(t *testing.T, o Options) *BotChecker {
+	t.Helper()
+
+	repo, err := memdb.NewPhraseMemDBRepository()
+	require.NoError(t, err)
+
+	ms := metrics.New()
+
+	o.MetricService = &ms
+	o.StopPhrasesRepository = &repo
+
+	if o.KaldiClient == nil {
+		o.KaldiClient = deafRecognizer{}
+	}
+
+	b, err := New(&o)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestPolicyWithQuery(t *testing.T) {
+	defaults := Policy{MaxListen: 120 * time.Second, HangupGrace: time.Second}
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		want    Policy
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: url.Values{},
+			want:  defaults,
+		},
+		{
+			name:  "overrides",
+			query: url.Values{"max_listen": {"30s"}, "decide_within": {"5s"}, "hangup_grace": {"0s"}},
+			want:  Policy{MaxListen: 30 * time.Second, DecideWithin: 5 * time.Second},
+		},
+		{
+			name:  "max listen capped",
+			query: url.Values{"max_listen": {"3h"}},
+			want:  defaults,
+		},
+		{
+			name:  "hangup grace capped",
+			query: url.Values{"hangup_grace": {"10h"}},
+			want:  defaults,
+		},
+		{
+			name:    "invalid duration",
+			query:   url.Values{"decide_within": {"soon"}},
+			wantErr: true,
+		},
+		{
+			name:    "zero max listen",
+			query:   url.Values{"max_listen": {"0s"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative grace",
+			query:   url.Values{"hangup_grace": {"-1s"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := defaults.withQuery(tt.query)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{name: "valid", policy: Policy{MaxListen: time.Second}},
+		{name: "no max listen", policy: Policy{}, wantErr: true},
+		{name: "negative window", policy: Policy{MaxListen: time.Second, DecideWithin: -1}, wantErr: true},
+		{name: "negative grace", policy: Policy{MaxListen: time.Second, HangupGrace: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				require.Error(t, tt.policy.Validate())
+			} else {
+				require.NoError(t, tt.policy.Validate())
+			}
+		})
+	}
+}
+
+func TestDecideWindowStartsWithAudio(t *testing.T) {
+	b := newOfflineChecker(t, Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	firstAudio := make(chan struct{})
+	done := make(chan Verdict, 1)
+
+	go func() {
+		v, _ := b.decide(ctx, cancel, make(chan models.KaldiMessage), make(chan error), nil, firstAudio, 20*time.Millisecond)
+		done <- v
+	}()
+
+	// no audio yet, the window has not started
+	select {
+	case <-done:
+		t.Fatal("decided before the audio started")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(firstAudio)
+
+	select {
+	case v := <-done:
+		require.Empty(t, v.Action)
+		require.NoError(t, ctx.Err())
+	case <-time.After(time.Second):
+		t.Fatal("window did not pass after the audio started")
+	}
+}
+
+func TestVerdictEventsCarryPolicy(t *testing.T) {
+	recorder := &eventRecorder{}
+	publisher := events.NewEventPublisher()
+	publisher.Subscribe(recorder, &checkevents.BotFound{}, &checkevents.BotNotFounded{})
+
+	b := newOfflineChecker(t, Options{
+		EventPublisher: publisher,
+		// hangup is left to the dialplan, there is no ARI here
+		DialplanDecides: true,
+	})
+
+	policy := Policy{MaxListen: 30 * time.Second, DecideWithin: 5 * time.Second, HangupGrace: 2 * time.Second}
+	want := checkevents.Policy{MaxListenMs: 30000, DecideWithinMs: 5000, HangupGraceMs: 2000}
+
+	b.HangupBot(context.Background(), call{ID: "call-1"}, Verdict{IsBot: true, Action: ActionHangupBot}, CheckStats{}, policy)
+	b.NotifyBotNotFound(call{ID: "call-2"}, CheckStats{}, policy)
+
+	require.Len(t, recorder.events, 2)
+	require.Equal(t, want, publishedPolicy(t, recorder.events[0]))
+	require.Equal(t, want, publishedPolicy(t, recorder.events[1]))
+}
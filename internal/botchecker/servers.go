@@ -0,0 +1,104 @@
+package botchecker
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/CyCoreSystems/ari"
+)
+
+// DefaultServer names the asterisk of AriClient unless AriName is set.
+const DefaultServer = "default"
+
+var ErrUnknownServer = errors.New("unknown asterisk server")
+
+// AriServer is an asterisk the checked calls come from, their channels are looked up,
+// hung up and given variables on it.
+type AriServer struct {
+	Name          string
+	Client        ari.Client
+	MediaChannels MediaChannels // optional, pulls the call audio over ARI
+	UserEvents    UserEvents    // optional, raises the verdict event
+	Channels      ChannelLister // optional, finds the calls streamed over AudioSocket
+}
+
+func (s *AriServer) channel(uniqID string) *ari.ChannelHandle {
+	return s.Client.Channel().Get(&ari.Key{
+		Kind: ari.ChannelKey,
+		ID:   uniqID,
+	})
+}
+
+// name is empty for a nil server, the checks without ARI.
+func (s *AriServer) name() string {
+	if s == nil {
+		return ""
+	}
+
+	return s.Name
+}
+
+// initServers indexes the asterisks by name, AriClient is the default one.
+func (b *BotChecker) initServers(servers []AriServer) error {
+	b.servers = make(map[string]*AriServer, len(servers)+1)
+
+	if b.AriClient != nil {
+		servers = append([]AriServer{{
+			Name:          b.AriName,
+			Client:        b.AriClient,
+			MediaChannels: b.MediaChannels,
+			UserEvents:    b.UserEvents,
+			Channels:      b.Channels,
+		}}, servers...)
+	}
+
+	for i := range servers {
+		s := servers[i]
+
+		switch {
+		case s.Name == "":
+			return errors.New("ARI server without a name")
+		case s.Client == nil:
+			return fmt.Errorf("ARI server %s without a client", s.Name)
+		case b.servers[s.Name] != nil:
+			return fmt.Errorf("ARI server %s is given twice", s.Name)
+		}
+
+		b.servers[s.Name] = &s
+		b.serverNames = append(b.serverNames, s.Name)
+	}
+
+	return nil
+}
+
+// server returns the asterisk of a check, the default one for an empty name.
+// It is nil for an empty name without ARI.
+func (b *BotChecker) server(name string) (*AriServer, error) {
+	if name == "" {
+		if len(b.servers) == 0 {
+			return nil, nil
+		}
+
+		name = b.AriName
+	}
+
+	s, ok := b.servers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownServer, name)
+	}
+
+	return s, nil
+}
+
+// serverFromQuery reads the server parameter naming the asterisk of the call.
+func (b *BotChecker) serverFromQuery(query url.Values) (*AriServer, error) {
+	return b.server(query.Get("server"))
+}
+
+// eachServer calls fn with every asterisk in the configured order.
+func (b *BotChecker) eachServer(fn func(s *AriServer)) {
+	for _, name := range b.serverNames {
+		fn(b.servers[name])
+	}
+}
@@ -0,0 +1,194 @@
+package botchecker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrSessionExists = errors.New("the call is being checked already")
+
+// SessionInfo is a running check as listed by the sessions API.
+type SessionInfo struct {
+	ID            string    `json:"id"`
+	Transport     string    `json:"transport"`
+	Server        string    `json:"server,omitempty"`
+	RemoteAddr    string    `json:"remote_addr"`
+	Backend       string    `json:"backend"`
+	StartedAt     time.Time `json:"started_at"`
+	BytesReceived int       `json:"bytes_received"`
+	Transcript    string    `json:"transcript"`
+}
+
+// session is a running check, its audio parts appear once the flow is started.
+type session struct {
+	id        string
+	transport string
+	server    string // asterisk of the call, empty without ARI
+	remote    string
+	backend   string
+	started   time.Time
+	text      *transcript
+	cancel    context.CancelFunc
+
+	mu        sync.Mutex
+	ingest    *ingest
+	canceled  bool
+	settled   bool
+	abandoned *hangupCause
+}
+
+// stop cancels the check on request, it ends without a verdict.
+func (s *session) stop() {
+	s.mu.Lock()
+	s.canceled = true
+	s.mu.Unlock()
+
+	s.cancel()
+}
+
+// abandon cancels the check of a call that hung up, false once the check has settled.
+func (s *session) abandon(cause hangupCause) bool {
+	s.mu.Lock()
+
+	if s.settled || s.abandoned != nil {
+		s.mu.Unlock()
+
+		return false
+	}
+
+	s.abandoned = &cause
+	s.mu.Unlock()
+
+	s.cancel()
+
+	return true
+}
+
+// settle ends the check, later hangups come from acting on its verdict.
+// It returns the cause when the call hung up before.
+func (s *session) settle() (hangupCause, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settled = true
+
+	if s.abandoned == nil {
+		return hangupCause{}, false
+	}
+
+	return *s.abandoned, true
+}
+
+func (s *session) isCanceled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.canceled
+}
+
+func (s *session) setIngest(in *ingest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ingest = in
+}
+
+func (s *session) info() SessionInfo {
+	s.mu.Lock()
+	in := s.ingest
+	s.mu.Unlock()
+
+	info := SessionInfo{
+		ID:         s.id,
+		Transport:  s.transport,
+		Server:     s.server,
+		RemoteAddr: s.remote,
+		Backend:    s.backend,
+		StartedAt:  s.started,
+		Transcript: s.text.String(),
+	}
+
+	if in != nil {
+		info.BytesReceived = in.received()
+	}
+
+	return info
+}
+
+// sessionKey identifies a call, uniqueids repeat across asterisks.
+type sessionKey struct {
+	server string
+	id     string
+}
+
+func (s *session) key() sessionKey {
+	return sessionKey{server: s.server, id: s.id}
+}
+
+// sessions are the checks running now by call.
+type sessions struct {
+	mu    sync.Mutex
+	byKey map[sessionKey]*session
+}
+
+func newSessions() *sessions {
+	return &sessions{byKey: make(map[sessionKey]*session)}
+}
+
+func (r *sessions) add(s *session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byKey[s.key()]; ok {
+		return ErrSessionExists
+	}
+
+	r.byKey[s.key()] = s
+
+	return nil
+}
+
+func (r *sessions) remove(s *session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byKey[s.key()] == s {
+		delete(r.byKey, s.key())
+	}
+}
+
+// get returns the check of the call id on server, server is empty without ARI.
+func (r *sessions) get(server, id string) (*session, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.byKey[sessionKey{server: server, id: id}]
+
+	return s, ok
+}
+
+// list returns the sessions, the oldest first.
+func (r *sessions) list() []SessionInfo {
+	r.mu.Lock()
+	all := make([]*session, 0, len(r.byKey))
+
+	for _, s := range r.byKey {
+		all = append(all, s)
+	}
+	r.mu.Unlock()
+
+	infos := make([]SessionInfo, 0, len(all))
+
+	for _, s := range all {
+		infos = append(infos, s.info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].StartedAt.Before(infos[j].StartedAt)
+	})
+
+	return infos
+}
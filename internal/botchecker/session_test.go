@@ -0,0 +1,158 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/ari/aritest"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func testSession(id string, started time.Time) *session {
+	_, cancel := context.WithCancel(context.Background())
+
+	return &session{
+		id:        id,
+		transport: TransportAudioFork,
+		started:   started,
+		text:      &transcript{},
+		cancel:    cancel,
+	}
+}
+
+func TestSessionAbandon(t *testing.T) {
+	s := testSession("call", time.Now())
+
+	require.True(t, s.abandon(newHangupCause(16, "")))
+	require.False(t, s.abandon(newHangupCause(17, "")))
+
+	cause, ok := s.settle()
+	require.True(t, ok)
+	require.Equal(t, hangupCause{Code: 16, Text: "Normal Clearing"}, cause)
+
+	// a hangup of a settled check follows its verdict
+	settled := testSession("settled", time.Now())
+	_, ok = settled.settle()
+	require.False(t, ok)
+	require.False(t, settled.abandon(newHangupCause(16, "")))
+}
+
+func TestSessions(t *testing.T) {
+	r := newSessions()
+	now := time.Now()
+
+	later := testSession("later", now.Add(time.Second))
+	earlier := testSession("earlier", now)
+
+	require.NoError(t, r.add(later))
+	require.NoError(t, r.add(earlier))
+	require.ErrorIs(t, r.add(testSession("later", now)), ErrSessionExists)
+
+	// the same uniqueid on another asterisk is another call
+	other := testSession("later", now.Add(2*time.Second))
+	other.server = "box2"
+	require.NoError(t, r.add(other))
+
+	s, ok := r.get("box2", "later")
+	require.True(t, ok)
+	require.Same(t, other, s)
+	r.remove(other)
+
+	list := r.list()
+	require.Len(t, list, 2)
+	require.Equal(t, "earlier", list[0].ID)
+	require.Equal(t, "later", list[1].ID)
+
+	later.setIngest(newIngest(nopWriteCloser{}))
+	_, _ = later.ingest.Write(make([]byte, 320))
+
+	s, ok = r.get("", "later")
+	require.True(t, ok)
+	require.Equal(t, 320, s.info().BytesReceived)
+
+	// a replaced session does not remove its successor
+	r.remove(testSession("earlier", now))
+	_, ok = r.get("", "earlier")
+	require.True(t, ok)
+
+	r.remove(earlier)
+	_, ok = r.get("", "earlier")
+	require.False(t, ok)
+}
+
+func TestCancelSessionHandler(t *testing.T) {
+	b := &BotChecker{sessions: newSessions()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := testSession("call-1", time.Now())
+	s.cancel = cancel
+
+	require.NoError(t, b.sessions.add(s))
+
+	router := chi.NewRouter()
+	router.Get("/api/sessions", b.SessionsHandler())
+	router.Delete("/api/sessions/{id}", b.CancelSessionHandler())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var listed struct {
+		Data []SessionInfo `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	require.Equal(t, "call-1", listed.Data[0].ID)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/sessions/unknown", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/sessions/call-1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, s.isCanceled())
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+type nopWriteCloser struct{}
+
+func (nopWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestCheckRefusedWhileRunning(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("call-1", nil)
+
+	b := newTestChecker(t, ariServer, mediaOptions(Options{KaldiClient: deafRecognizer{}}))
+
+	running := testSession("call-1", time.Now())
+	running.server = DefaultServer
+	require.NoError(t, b.sessions.add(running))
+
+	router := chi.NewRouter()
+	router.Handle("/bot-check/{uniqID}", b.CheckBotHandler())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bot-check/call-1", nil))
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	resp := postMediaCheck(t, b, "/bot-check/call-1/ari")
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// neither the media nor a hangup subscription was created
+	require.Empty(t, ariServer.Calls())
+}
@@ -0,0 +1,74 @@
+package botchecker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+// speechTimeline collects VAD events of a call: the first speech segment is the greeting.
+type speechTimeline struct {
+	mu sync.Mutex
+
+	greetingStart time.Duration
+	greetingEnd   time.Duration
+	nextSpeech    time.Duration
+	started       bool
+	ended         bool
+	resumed       bool
+}
+
+type SpeechStats struct {
+	Greeting             time.Duration
+	SilenceAfterGreeting time.Duration
+}
+
+func (s *speechTimeline) add(ev audio.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch ev.Type {
+	case audio.EventSpeechStart:
+		switch {
+		case !s.started:
+			s.started = true
+			s.greetingStart = ev.At
+		case s.ended && !s.resumed:
+			s.resumed = true
+			s.nextSpeech = ev.At
+		}
+	case audio.EventSpeechEnd:
+		if s.started && !s.ended {
+			s.ended = true
+			s.greetingEnd = ev.At
+		}
+	}
+}
+
+// stats measures the greeting and the silence after it up to position, the end of analysed audio.
+func (s *speechTimeline) stats(position time.Duration) SpeechStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res SpeechStats
+
+	switch {
+	case !s.started:
+		return res
+	case !s.ended:
+		res.Greeting = position - s.greetingStart
+
+		return res
+	}
+
+	res.Greeting = s.greetingEnd - s.greetingStart
+
+	if s.resumed {
+		res.SilenceAfterGreeting = s.nextSpeech - s.greetingEnd
+	} else {
+		res.SilenceAfterGreeting = position - s.greetingEnd
+	}
+
+	return res
+}
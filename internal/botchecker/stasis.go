@@ -0,0 +1,141 @@
+package botchecker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/observability/logger"
+	"github.com/CyCoreSystems/ari"
+	"go.uber.org/zap"
+)
+
+const (
+	ResultBot       = "bot"
+	ResultFax       = "fax"
+	ResultHuman     = "human"
+	ResultUndecided = "undecided"
+	ResultAbandoned = "abandoned"
+)
+
+// runStasis checks every call entering the application on server until ctx is done.
+// A call is hung up on a bot verdict and goes back to the dialplan otherwise.
+func (b *BotChecker) runStasis(ctx context.Context, server *AriServer) {
+	sub := server.Client.Bus().Subscribe(nil, ari.Events.StasisStart)
+	defer sub.Cancel()
+
+	logger.L().Info("ARI auto-check started",
+		zap.String("server", server.Name), zap.String("app", server.Client.ApplicationName()))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			start, ok := ev.(*ari.StasisStart)
+			if !ok || isMediaChannel(start.Channel.ID) {
+				continue
+			}
+
+			go b.autoCheck(ctx, server, start.Channel.ID)
+		}
+	}
+}
+
+// autoCheck runs the check of a call in the application and hands the call back.
+func (b *BotChecker) autoCheck(ctx context.Context, server *AriServer, uniqID string) {
+	replier := &stasisReplier{}
+
+	defer b.continueDialplan(server, uniqID, replier)
+
+	release, err := b.admission.acquire(ctx, b.KaldiBackend)
+	if err != nil {
+		logger.L().Warn("bot check rejected", zap.String("call", uniqID), zap.Error(err))
+
+		return
+	}
+	defer release()
+
+	b.Metrics.StoreIvrCheckStart()
+
+	ctx, cancel := context.WithTimeout(ctx, b.Policy.MaxListen)
+	defer cancel()
+
+	session, err := b.startMedia(ctx, server, uniqID)
+	if err != nil {
+		logger.L().Error("unable start ARI media", zap.String("call", uniqID), zap.Error(err))
+
+		return
+	}
+	defer session.close()
+
+	req := b.mediaRequest(server, uniqID, "stasis", b.Policy, session)
+	req.Replier = replier
+
+	replier.hungUp = b.runCheck(ctx, cancel, req)
+}
+
+// continueDialplan returns the call to the dialplan with BOTCHECK_RESULT set,
+// unless the check hung it up or the caller did. A call the check failed to hang up goes on.
+func (b *BotChecker) continueDialplan(server *AriServer, uniqID string, replier *stasisReplier) {
+	result := replier.result()
+	if result == ResultAbandoned || replier.hungUp {
+		return
+	}
+
+	channel := server.channel(uniqID)
+
+	// the check sets it with the other outcome variables
+	if !b.VerdictVariables || result == ResultUndecided {
+		if err := channel.SetVariable(VarResult, result); err != nil {
+			logger.L().Error("unable set check result", zap.String("call", uniqID), zap.Error(err))
+		}
+	}
+
+	if err := channel.Continue("", "", 0); err != nil {
+		logger.L().Error("unable continue dialplan", zap.String("call", uniqID), zap.Error(err))
+	}
+}
+
+func isMediaChannel(id string) bool {
+	return strings.HasSuffix(id, snoopSuffix) || strings.HasSuffix(id, mediaSuffix)
+}
+
+// stasisReplier keeps the verdict of an auto check.
+type stasisReplier struct {
+	verdictResult string
+	hungUp        bool
+}
+
+func (r *stasisReplier) transcript(models.KaldiMessage) {}
+
+func (r *stasisReplier) verdict(v Verdict, _ string) {
+	r.verdictResult = verdictResult(v)
+}
+
+// result is the outcome of the check, undecided when it ended without a verdict.
+func (r *stasisReplier) result() string {
+	if r.verdictResult == "" {
+		return ResultUndecided
+	}
+
+	return r.verdictResult
+}
+
+// verdictResult names the outcome of a verdict for the dialplan.
+func verdictResult(v Verdict) string {
+	switch v.Action {
+	case ActionHangupBot:
+		return ResultBot
+	case ActionHangupFax:
+		return ResultFax
+	case ActionAbandoned:
+		return ResultAbandoned
+	default:
+		return ResultHuman
+	}
+}
@@ -0,0 +1,192 @@
+//go:build test && !integration
+
+package botchecker
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	ariclient "github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/Arten331/bot-checker/pkg/ari/aritest"
+	"github.com/Arten331/bot-checker/pkg/kaldi"
+	"github.com/stretchr/testify/require"
+)
+
+// mediaOptions pull the call audio as slin RTP sent to the loopback.
+func mediaOptions(o Options) Options {
+	o.MediaHost = "127.0.0.1"
+	o.MediaListen = "127.0.0.1"
+	o.MediaFormat = "slin"
+
+	return o
+}
+
+// sendRTP streams a tone as asterisk does to the externalMedia destination requested from ariServer,
+// skip sequence numbers are not sent.
+func sendRTP(ctx context.Context, t *testing.T, ariServer *aritest.Server, skip func(seq uint16) bool) {
+	t.Helper()
+
+	var host string
+
+	require.Eventually(t, func() bool {
+		for _, req := range ariServer.Requests() {
+			if req.Path == "/channels/externalMedia" {
+				host = req.Query.Get("external_host")
+			}
+		}
+
+		return host != ""
+	}, 5*time.Second, 5*time.Millisecond)
+
+	conn, err := net.Dial("udp", host)
+	require.NoError(t, err)
+
+	// slin payloads are big endian
+	payload := make([]byte, 320)
+	for i := 0; i < len(payload)/2; i++ {
+		binary.BigEndian.PutUint16(payload[2*i:], uint16(int16(8000*math.Sin(2*math.Pi*440*float64(i)/8000))))
+	}
+
+	go func() {
+		defer func() { _ = conn.Close() }()
+
+		packet := make([]byte, 12+len(payload))
+		packet[0] = 2 << 6
+		binary.BigEndian.PutUint32(packet[8:], 0x1234)
+		copy(packet[12:], payload)
+
+		for seq := uint16(1); ctx.Err() == nil; seq++ {
+			if skip != nil && skip(seq) {
+				continue
+			}
+
+			binary.BigEndian.PutUint16(packet[2:], seq)
+			binary.BigEndian.PutUint32(packet[4:], uint32(seq)*160)
+
+			if _, err := conn.Write(packet); err != nil {
+				return
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}()
+}
+
+func TestStasisReplier(t *testing.T) {
+	r := &stasisReplier{}
+	require.Equal(t, ResultUndecided, r.result())
+
+	r.verdict(Verdict{}, "")
+	require.Equal(t, ResultHuman, r.result())
+
+	r.verdict(Verdict{IsBot: true, Action: ActionHangupBot}, "")
+	require.Equal(t, ResultBot, r.result())
+
+	r.verdict(Verdict{Action: ActionHangupFax}, "")
+	require.Equal(t, ResultFax, r.result())
+}
+
+func TestIsMediaChannel(t *testing.T) {
+	require.True(t, isMediaChannel("1650000000.12"+snoopSuffix))
+	require.True(t, isMediaChannel("1650000000.12"+mediaSuffix))
+	require.False(t, isMediaChannel("1650000000.12"))
+}
+
+func TestContinueDialplanAfterBotVerdict(t *testing.T) {
+	ariServer := aritest.NewServer()
+	defer ariServer.Close()
+
+	ariServer.AddChannel("hung-up", nil)
+	ariServer.AddChannel("still-up", nil)
+
+	b := newTestChecker(t, ariServer, Options{KaldiClient: deafRecognizer{}})
+	server := b.servers[DefaultServer]
+
+	b.continueDialplan(server, "hung-up", &stasisReplier{verdictResult: ResultBot, hungUp: true})
+
+	ch, _ := ariServer.Channel("hung-up")
+	require.False(t, ch.Continued)
+
+	// the hangup failed, the call must not stay in the application
+	b.continueDialplan(server, "still-up", &stasisReplier{verdictResult: ResultBot})
+
+	ch, _ = ariServer.Channel("still-up")
+	require.True(t, ch.Continued)
+	require.Equal(t, ResultBot, ch.Variables[VarResult])
+}
+
+func TestAutoCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		hungUp    bool
+		continued bool
+		result    string
+	}{
+		{name: "bot", text: "оставьте сообщение после сигнала", hungUp: true},
+		{name: "human", continued: true, result: ResultHuman},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ariServer := aritest.NewServer()
+			defer ariServer.Close()
+
+			ariServer.AddChannel("call-1", nil)
+
+			var recognizer kaldi.Recognizer = deafRecognizer{}
+			if tt.text != "" {
+				recognizer = scriptedRecognizer{text: tt.text}
+			}
+
+			b := newTestChecker(t, ariServer, mediaOptions(Options{
+				KaldiClient: recognizer,
+				AutoCheck:   true,
+				Policy:      Policy{MaxListen: 5 * time.Second, DecideWithin: 200 * time.Millisecond},
+			}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go b.AriClient.(*ariclient.Client).Run(ctx, cancel)
+			go b.runStasis(ctx, b.servers[DefaultServer])
+
+			require.Eventually(t, func() bool { return ariServer.Listeners() > 0 }, 5*time.Second, 5*time.Millisecond)
+
+			ariServer.StasisStart("call-1")
+			sendRTP(ctx, t, ariServer, nil)
+
+			require.Eventually(t, func() bool {
+				ch, _ := ariServer.Channel("call-1")
+
+				return ch.HungUp || ch.Continued
+			}, 5*time.Second, 10*time.Millisecond)
+
+			ch, _ := ariServer.Channel("call-1")
+			require.Equal(t, tt.hungUp, ch.HungUp)
+			require.Equal(t, tt.continued, ch.Continued)
+			require.Equal(t, tt.result, ch.Variables[VarResult])
+
+			// the media is torn down with the check
+			require.Eventually(t, func() bool {
+				media, _ := ariServer.Channel("call-1" + mediaSuffix)
+				snoop, _ := ariServer.Channel("call-1" + snoopSuffix)
+
+				return media.HungUp && snoop.HungUp
+			}, 5*time.Second, 10*time.Millisecond)
+
+			calls := ariServer.Calls()
+			require.Subset(t, calls, []string{
+				"POST /channels/call-1/snoop/call-1-snoop",
+				"POST /channels/externalMedia",
+				"POST /bridges/call-1-bridge",
+				"POST /bridges/call-1-bridge/addChannel",
+				"DELETE /bridges/call-1-bridge",
+			})
+		})
+	}
+}
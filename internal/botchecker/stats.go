@@ -0,0 +1,124 @@
+package botchecker
+
+import (
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/botchecker/metrics"
+	checkevents "github.com/Arten331/bot-checker/internal/events/bot_checker"
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+// ingestJitter is the delay of a realtime stream not counted as dropped frames.
+const ingestJitter = 3 * audio.FrameDuration
+
+// CheckStats describe the audio of a checked call.
+type CheckStats struct {
+	Speech        SpeechStats
+	Quality       audio.QualityStats
+	DroppedFrames int
+}
+
+// ingest passes client audio to w and counts it to find frames lost on the way.
+type ingest struct {
+	w       io.WriteCloser
+	started chan struct{} // closed by the first audio
+
+	mu    sync.Mutex
+	first time.Time
+	last  time.Time
+	bytes int
+}
+
+func newIngest(w io.WriteCloser) *ingest {
+	return &ingest{w: w, started: make(chan struct{})}
+}
+
+func (i *ingest) Write(p []byte) (int, error) {
+	now := time.Now()
+
+	i.mu.Lock()
+	if i.first.IsZero() {
+		i.first = now
+		close(i.started)
+	}
+
+	i.last = now
+	i.bytes += len(p)
+	i.mu.Unlock()
+
+	return i.w.Write(p)
+}
+
+func (i *ingest) Close() error {
+	return i.w.Close()
+}
+
+// firstAudio is closed once the client audio starts.
+func (i *ingest) firstAudio() <-chan struct{} {
+	return i.started
+}
+
+// received is the count of audio bytes passed so far.
+func (i *ingest) received() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.bytes
+}
+
+// droppedFrames estimates frames missing from a realtime stream of format:
+// the time the audio took to arrive beyond its own duration.
+func (i *ingest) droppedFrames(format audio.Format) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	bytesPerSecond := format.SampleRate * format.Channels * format.SampleSize()
+	if i.bytes == 0 || bytesPerSecond == 0 {
+		return 0
+	}
+
+	received := time.Duration(float64(i.bytes) / float64(bytesPerSecond) * float64(time.Second))
+	missing := i.last.Sub(i.first) + audio.FrameDuration - received - ingestJitter
+
+	if missing <= 0 {
+		return 0
+	}
+
+	return int(missing / audio.FrameDuration)
+}
+
+func (s CheckStats) eventQuality() checkevents.AudioQuality {
+	return checkevents.AudioQuality{
+		RMSDB:         finiteDB(s.Quality.RMSDB),
+		PeakDB:        finiteDB(s.Quality.PeakDB),
+		ClippingRatio: s.Quality.ClippingRatio,
+		SilenceRatio:  s.Quality.SilenceRatio,
+		SpeechMs:      s.Quality.Speech.Milliseconds(),
+		DroppedFrames: s.DroppedFrames,
+	}
+}
+
+func (s CheckStats) metricsQuality() metrics.AudioQuality {
+	return metrics.AudioQuality{
+		RMSDB:         finiteDB(s.Quality.RMSDB),
+		PeakDB:        finiteDB(s.Quality.PeakDB),
+		ClippingRatio: s.Quality.ClippingRatio,
+		SilenceRatio:  s.Quality.SilenceRatio,
+		SpeechSeconds: s.Quality.Speech.Seconds(),
+		DroppedFrames: s.DroppedFrames,
+	}
+}
+
+// finiteDB clamps the level of digital silence, JSON has no infinity.
+func finiteDB(db float64) float64 {
+	const floor = -96 // 16-bit dynamic range
+
+	if math.IsInf(db, -1) || db < floor {
+		return floor
+	}
+
+	return db
+}
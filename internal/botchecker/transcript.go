@@ -0,0 +1,76 @@
+package botchecker
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Arten331/bot-checker/internal/models"
+)
+
+// transcript collects recognizer results of a call on their way to Check.
+type transcript struct {
+	mu      sync.Mutex
+	final   []string
+	partial string
+
+	onMessage func(msg models.KaldiMessage) // optional, sees every message
+}
+
+// tee returns a channel with every message of in, recording the texts.
+func (t *transcript) tee(ctx context.Context, in <-chan models.KaldiMessage) <-chan models.KaldiMessage {
+	out := make(chan models.KaldiMessage)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-in:
+				t.add(msg)
+
+				if t.onMessage != nil {
+					t.onMessage(msg)
+				}
+
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (t *transcript) add(msg models.KaldiMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !msg.IsFinal {
+		t.partial = string(msg.Text)
+
+		return
+	}
+
+	if len(msg.Text) > 0 {
+		t.final = append(t.final, string(msg.Text))
+	}
+
+	t.partial = ""
+}
+
+// String returns final results and the last partial one after them.
+func (t *transcript) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parts := t.final
+	if t.partial != "" {
+		parts = append(parts[:len(parts):len(parts)], t.partial)
+	}
+
+	return strings.Join(parts, " ")
+}
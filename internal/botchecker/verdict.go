@@ -0,0 +1,226 @@
+package botchecker
+
+import (
+	"context"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/domain/phrase"
+	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/Arten331/observability/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	SourceSpeech = "speech"
+	SourceBeep   = "beep"
+	SourceSIT    = "sit"
+	SourceFax    = "fax"
+	SourceHangup = "hangup"
+
+	CategoryVoicemailBeep = "voicemail_beep"
+	CategoryDisconnected  = "disconnected"
+	CategoryFax           = "fax"
+	CategoryAbandoned     = "abandoned"
+
+	// ActionHangupBot hangs up the call and reports the bot.
+	ActionHangupBot = "hangup_bot"
+	// ActionHangupFax hangs up the call and reports the fax machine.
+	ActionHangupFax = "hangup_fax"
+	// ActionAbandoned reports a call that hung up before the verdict, it is gone already.
+	ActionAbandoned = "abandoned"
+)
+
+// Verdict is the outcome of a check, decided by a stop phrase or by an audio signal.
+// Action is empty when nothing was decided.
+type Verdict struct {
+	IsBot    bool
+	Action   string
+	Source   string
+	Category string
+	Detail   string // signal label: the beep frequency, the SIT variant
+	Phrase   *phrase.StopPhrase
+}
+
+func speechVerdict(p *phrase.StopPhrase) Verdict {
+	return Verdict{
+		IsBot:    true,
+		Action:   ActionHangupBot,
+		Source:   SourceSpeech,
+		Category: p.Category.Name(),
+		Phrase:   p,
+	}
+}
+
+func abandonedVerdict(cause hangupCause) Verdict {
+	return Verdict{
+		Action:   ActionAbandoned,
+		Source:   SourceHangup,
+		Category: CategoryAbandoned,
+		Detail:   cause.String(),
+	}
+}
+
+// signalVerdict maps an analysis event to a verdict, false for events that decide nothing.
+func signalVerdict(ev audio.Event) (Verdict, bool) {
+	switch ev.Type { //nolint:exhaustive // speech events are not verdicts
+	case audio.EventBeep:
+		return Verdict{
+			IsBot:    true,
+			Action:   ActionHangupBot,
+			Source:   SourceBeep,
+			Category: CategoryVoicemailBeep,
+			Detail:   ev.Label,
+		}, true
+	case audio.EventSIT:
+		return Verdict{
+			IsBot:    true,
+			Action:   ActionHangupBot,
+			Source:   SourceSIT,
+			Category: CategoryDisconnected,
+			Detail:   ev.Label,
+		}, true
+	case audio.EventFax:
+		return Verdict{
+			Action:   ActionHangupFax,
+			Source:   SourceFax,
+			Category: CategoryFax,
+			Detail:   ev.Label,
+		}, true
+	default:
+		return Verdict{}, false
+	}
+}
+
+// PhraseText returns the stop phrase of the verdict, empty for signal verdicts.
+func (v Verdict) PhraseText() string {
+	if v.Phrase == nil {
+		return ""
+	}
+
+	return v.Phrase.Phrase
+}
+
+func (v Verdict) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddBool("is_bot", v.IsBot)
+	encoder.AddString("action", v.Action)
+	encoder.AddString("source", v.Source)
+	encoder.AddString("category", v.Category)
+	encoder.AddString("detail", v.Detail)
+	encoder.AddString("phrase", v.PhraseText())
+
+	return nil
+}
+
+type speechResult struct {
+	isBot  bool
+	phrase *phrase.StopPhrase
+	err    error
+}
+
+// decide waits for the first bot verdict from the recognizer or from the audio signals.
+// A recognized phrase that is not a stop phrase does not end the check, signals are awaited
+// until ctx is done or, with a positive window, until the window passes after firstAudio is closed.
+func (b *BotChecker) decide(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	mshCh <-chan models.KaldiMessage,
+	errCh chan error,
+	signals <-chan Verdict,
+	firstAudio <-chan struct{},
+	window time.Duration,
+) (Verdict, error) {
+	speechCh := make(chan speechResult, 1)
+
+	var (
+		audioStarted <-chan struct{}
+		windowEnd    <-chan time.Time
+		timer        *time.Timer
+	)
+
+	if window > 0 {
+		audioStarted = firstAudio
+	}
+
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	go func() {
+		isBot, stopPhrase, err := b.Check(ctx, cancel, mshCh, errCh)
+		speechCh <- speechResult{isBot: isBot, phrase: stopPhrase, err: err}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Verdict{}, nil
+		case <-audioStarted:
+			audioStarted = nil
+			timer = time.NewTimer(window)
+			windowEnd = timer.C
+		case <-windowEnd:
+			logger.L().Debug("decision window passed, later matches are ignored")
+
+			return Verdict{}, nil
+		case res := <-speechCh:
+			if res.err != nil {
+				return Verdict{}, res.err
+			}
+
+			if res.isBot {
+				return speechVerdict(res.phrase), nil
+			}
+
+			speechCh = nil
+		case v := <-signals:
+			return v, nil
+		}
+	}
+}
+
+// watchAnalysis records speech events of the call and forwards signal verdicts.
+func watchAnalysis(ctx context.Context, events <-chan audio.Event, speech *speechTimeline, signals chan<- Verdict) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+
+			logger.L().Debug("analysis event",
+				zap.String("type", string(ev.Type)),
+				zap.Duration("at", ev.At),
+				zap.Duration("duration", ev.Duration),
+				zap.String("label", ev.Label),
+			)
+
+			speech.add(ev)
+
+			if v, ok := signalVerdict(ev); ok {
+				select {
+				case signals <- v:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (b *BotChecker) storeVerdict(v Verdict) {
+	switch v.Source {
+	case SourceSpeech:
+		b.Metrics.StoreIvrCheckHangup(v.Phrase)
+	case SourceBeep:
+		b.Metrics.StoreVoicemailBeep()
+	case SourceSIT:
+		b.Metrics.StoreDisconnected(v.Detail)
+	case SourceFax:
+		b.Metrics.StoreFax(v.Detail)
+	}
+}
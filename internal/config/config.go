@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const appName = "bot-checker"
@@ -18,13 +19,80 @@ type Agi struct {
 	Port int
 }
 
+type AudioSocket struct {
+	Enabled bool
+	Host    string
+	Port    int
+}
+
 type Ari struct {
+	Name     string
 	Host     string
 	Port     int
 	User     string
 	Password string
 	Original string
 	Secure   bool
+
+	PingInterval time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+
+	MediaHost   string
+	MediaListen string
+	MediaFormat string
+	AutoCheck   bool
+
+	Servers []AriServer
+}
+
+// AriServer is another asterisk the calls come from, unset values are taken from the main one.
+type AriServer struct {
+	Name     string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Secure   bool
+}
+
+type Audio struct {
+	Backend         string
+	VadEnabled      bool
+	VadSkipSilence  time.Duration
+	BeepEnabled     bool
+	BeepFrequency   float64
+	BeepMinDuration time.Duration
+	BeepThreshold   float64
+	SITEnabled      bool
+	SITThreshold    float64
+	FaxEnabled      bool
+	FaxThreshold    float64
+	RemoteChannel   int
+	PreRoll         time.Duration
+}
+
+type Check struct {
+	MaxListen    time.Duration
+	DecideWithin time.Duration
+	HangupGrace  time.Duration
+
+	MaxActive     int
+	MaxPerBackend int
+	QueueSize     int
+	QueueTimeout  time.Duration
+
+	VerdictVariables bool
+	VerdictEvent     string
+	DialplanDecides  bool
+}
+
+type Recording struct {
+	Enabled         bool
+	Dir             string
+	MaxAge          time.Duration
+	MaxSizeMB       int
+	CleanupInterval time.Duration
 }
 
 type HTTPService struct {
@@ -40,14 +108,21 @@ type AppConfig struct {
 	Logger       Logger
 	HTTPService  HTTPService
 	Agi          Agi
+	AudioSocket  AudioSocket
 	Kaldi        Kaldi
+	Audio        Audio
+	Check        Check
+	Recording    Recording
 	Ari          Ari
 	QueueService QueueConfig
 }
 
 type Kaldi struct {
-	Host string
-	Port int
+	Host       string
+	Port       int
+	Transport  string
+	GRPCPort   int
+	SampleRate int
 }
 
 type QueueConfig struct {
@@ -88,17 +163,71 @@ func Init() (AppConfig, error) {
 			Host: GetEnvAsStr("AGI_HOST", ""),
 			Port: GetEnvAsInt("AGI_PORT", 8888),
 		},
+		AudioSocket: AudioSocket{
+			Enabled: GetEnvAsBool("AUDIOSOCKET_ENABLED", false),
+			Host:    GetEnvAsStr("AUDIOSOCKET_HOST", ""),
+			Port:    GetEnvAsInt("AUDIOSOCKET_PORT", 8889),
+		},
 		Kaldi: Kaldi{
-			Host: GetEnvAsStr("KALDI_HOST", "localhost"),
-			Port: GetEnvAsInt("KALDI_PORT", 2700),
+			Host:       GetEnvAsStr("KALDI_HOST", "localhost"),
+			Port:       GetEnvAsInt("KALDI_PORT", 2700),
+			Transport:  GetEnvAsStr("KALDI_TRANSPORT", "ws"),
+			GRPCPort:   GetEnvAsInt("KALDI_GRPC_PORT", 5001),
+			SampleRate: GetEnvAsInt("KALDI_SAMPLE_RATE", 8000),
+		},
+		Audio: Audio{
+			Backend:         GetEnvAsStr("AUDIO_BACKEND", "sox"),
+			VadEnabled:      GetEnvAsBool("VAD_ENABLED", true),
+			VadSkipSilence:  GetEnvAsDuration("VAD_SKIP_SILENCE", 0),
+			BeepEnabled:     GetEnvAsBool("BEEP_ENABLED", false),
+			BeepFrequency:   GetEnvAsFloat("BEEP_FREQUENCY", 1000),
+			BeepMinDuration: GetEnvAsDuration("BEEP_MIN_DURATION", 200*time.Millisecond),
+			BeepThreshold:   GetEnvAsFloat("BEEP_THRESHOLD_DB", -40),
+			SITEnabled:      GetEnvAsBool("SIT_ENABLED", false),
+			SITThreshold:    GetEnvAsFloat("SIT_THRESHOLD_DB", -40),
+			FaxEnabled:      GetEnvAsBool("FAX_ENABLED", false),
+			FaxThreshold:    GetEnvAsFloat("FAX_THRESHOLD_DB", -40),
+			RemoteChannel:   GetEnvAsInt("AUDIO_REMOTE_CHANNEL", 0),
+			PreRoll:         GetEnvAsDuration("AUDIO_PREROLL", 2*time.Second),
+		},
+		Check: Check{
+			MaxListen:    GetEnvAsDuration("CHECK_MAX_LISTEN", 120*time.Second),
+			DecideWithin: GetEnvAsDuration("CHECK_DECIDE_WITHIN", 0),
+			HangupGrace:  GetEnvAsDuration("CHECK_HANGUP_GRACE", time.Second),
+
+			MaxActive:     GetEnvAsInt("CHECK_MAX_ACTIVE", 0),
+			MaxPerBackend: GetEnvAsInt("CHECK_MAX_PER_BACKEND", 0),
+			QueueSize:     GetEnvAsInt("CHECK_QUEUE_SIZE", 0),
+			QueueTimeout:  GetEnvAsDuration("CHECK_QUEUE_TIMEOUT", 2*time.Second),
+
+			VerdictVariables: GetEnvAsBool("CHECK_VERDICT_VARIABLES", false),
+			VerdictEvent:     GetEnvAsStr("CHECK_VERDICT_EVENT", ""),
+			DialplanDecides:  GetEnvAsBool("CHECK_DIALPLAN_DECIDES", false),
+		},
+		Recording: Recording{
+			Enabled:         GetEnvAsBool("RECORDING_ENABLED", false),
+			Dir:             GetEnvAsStr("RECORDING_DIR", "/tmp/botrec"),
+			MaxAge:          GetEnvAsDuration("RECORDING_MAX_AGE", 7*24*time.Hour),
+			MaxSizeMB:       GetEnvAsInt("RECORDING_MAX_SIZE_MB", 1024),
+			CleanupInterval: GetEnvAsDuration("RECORDING_CLEANUP_INTERVAL", 10*time.Minute),
 		},
 		Ari: Ari{
+			Name:     GetEnvAsStr("ARI_NAME", "default"),
 			Host:     GetEnvAsStr("ARI_HOST", "asterisk.local"),
 			Port:     GetEnvAsInt("ARI_PORT", 8089),
 			Secure:   GetEnvAsBool("ARI_SECURE", true),
 			User:     GetEnvAsStr("ARI_USER", "bot_checker"),
 			Password: GetEnvAsStr("ARI_PASS", "bot_checker"),
 			Original: GetEnvAsStr("ARI_ORIG", "http://bot-checker.local"),
+
+			PingInterval: GetEnvAsDuration("ARI_PING_INTERVAL", 5*time.Second),
+			MinBackoff:   GetEnvAsDuration("ARI_RECONNECT_MIN", 500*time.Millisecond),
+			MaxBackoff:   GetEnvAsDuration("ARI_RECONNECT_MAX", 30*time.Second),
+
+			MediaHost:   GetEnvAsStr("ARI_MEDIA_HOST", ""),
+			MediaListen: GetEnvAsStr("ARI_MEDIA_LISTEN", ""),
+			MediaFormat: GetEnvAsStr("ARI_MEDIA_FORMAT", "ulaw"),
+			AutoCheck:   GetEnvAsBool("ARI_AUTO_CHECK", false),
 		},
 		QueueService: QueueConfig{
 			Kafka: KafkaBroker{
@@ -114,9 +243,37 @@ func Init() (AppConfig, error) {
 		},
 	}
 
+	config.Ari.Servers = ariServers(config.Ari)
+
 	return config, nil
 }
 
+// ariServers reads the asterisks listed in ARI_SERVERS, ARI_<NAME>_HOST and so on describe each one.
+func ariServers(main Ari) []AriServer {
+	names := GetEnvAsStrSlice("ARI_SERVERS", []string{})
+	servers := make([]AriServer, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "ARI_" + strings.ToUpper(name) + "_"
+
+		servers = append(servers, AriServer{
+			Name:     name,
+			Host:     GetEnvAsStr(prefix+"HOST", main.Host),
+			Port:     GetEnvAsInt(prefix+"PORT", main.Port),
+			User:     GetEnvAsStr(prefix+"USER", main.User),
+			Password: GetEnvAsStr(prefix+"PASS", main.Password),
+			Secure:   GetEnvAsBool(prefix+"SECURE", main.Secure),
+		})
+	}
+
+	return servers
+}
+
 func GetEnvAsStr(key, defaultVal string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -142,6 +299,15 @@ func GetEnvAsInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func GetEnvAsFloat(key string, defaultVal float64) float64 {
+	valStr := GetEnvAsStr(key, "")
+	if val, err := strconv.ParseFloat(valStr, 64); err == nil {
+		return val
+	}
+
+	return defaultVal
+}
+
 func GetEnvAsBool(key string, defaultVal bool) bool {
 	valStr := GetEnvAsStr(key, "")
 	if val, err := strconv.ParseBool(valStr); err == nil {
@@ -150,3 +316,12 @@ func GetEnvAsBool(key string, defaultVal bool) bool {
 
 	return defaultVal
 }
+
+func GetEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
+	valStr := GetEnvAsStr(key, "")
+	if val, err := time.ParseDuration(valStr); err == nil {
+		return val
+	}
+
+	return defaultVal
+}
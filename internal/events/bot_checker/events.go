@@ -13,6 +13,8 @@ import (
 const (
 	KeyBotFound          = "bot_checker_robot_found"
 	KeyBotNotFound       = "bot_checker_robot_not_found"
+	KeyFaxFound          = "bot_checker_fax_found"
+	KeyCallAbandoned     = "bot_checker_call_abandoned"
 	typeBotCheckerResult = "bot_checker_result"
 )
 
@@ -29,12 +31,36 @@ type ClickKafkaMessage struct {
 	Data        string `json:"data"`
 }
 
+// AudioQuality describes the call audio, levels are in dBFS.
+type AudioQuality struct {
+	RMSDB         float64 `json:"rms_db"`
+	PeakDB        float64 `json:"peak_db"`
+	ClippingRatio float64 `json:"clipping_ratio"`
+	SilenceRatio  float64 `json:"silence_ratio"`
+	SpeechMs      int64   `json:"speech_ms"`
+	DroppedFrames int     `json:"dropped_frames"`
+}
+
+// Policy is the time budget the check ran with.
+type Policy struct {
+	MaxListenMs    int64 `json:"max_listen_ms"`
+	DecideWithinMs int64 `json:"decide_within_ms"`
+	HangupGraceMs  int64 `json:"hangup_grace_ms"`
+}
+
 type BotFound struct {
-	CallID    string `json:"id"`
-	Dest      string `json:"dnid"`
-	From      string `json:"from"`
-	Phrase    string `json:"phrase"`
-	EventName string `json:"event_name"`
+	CallID                 string       `json:"id"`
+	Dest                   string       `json:"dnid"`
+	From                   string       `json:"from"`
+	Phrase                 string       `json:"phrase"`
+	Category               string       `json:"category"`
+	Source                 string       `json:"source"`
+	Detail                 string       `json:"detail,omitempty"`
+	GreetingMs             int64        `json:"greeting_ms"`
+	SilenceAfterGreetingMs int64        `json:"silence_after_greeting_ms"`
+	AudioQuality           AudioQuality `json:"audio_quality"`
+	Policy                 Policy       `json:"policy"`
+	EventName              string       `json:"event_name"`
 }
 
 func (e *BotFound) Name() string {
@@ -56,18 +82,80 @@ func (e *BotFound) KafkaMessage() (kafka.Message, error) {
 }
 
 type BotNotFounded struct {
+	CallID                 string       `json:"id"`
+	Dest                   string       `json:"dnid"`
+	From                   string       `json:"from"`
+	Phrase                 string       `json:"phrase"`
+	GreetingMs             int64        `json:"greeting_ms"`
+	SilenceAfterGreetingMs int64        `json:"silence_after_greeting_ms"`
+	AudioQuality           AudioQuality `json:"audio_quality"`
+	Policy                 Policy       `json:"policy"`
+	EventName              string       `json:"event_name"`
+}
+
+func (e *BotNotFounded) Name() string {
+	return KeyBotNotFound
+}
+
+func (e *BotNotFounded) KafkaMessage() (kafka.Message, error) {
+	km := NewClickKafkaMessage(e)
+
+	msg, err := json.Marshal(km)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Key:   []byte(e.Name()),
+		Value: msg,
+	}, nil
+}
+
+type FaxFound struct {
 	CallID    string `json:"id"`
 	Dest      string `json:"dnid"`
 	From      string `json:"from"`
-	Phrase    string `json:"phrase"`
+	Tone      string `json:"tone"`
+	Policy    Policy `json:"policy"`
 	EventName string `json:"event_name"`
 }
 
-func (e *BotNotFounded) Name() string {
-	return KeyBotNotFound
+func (e *FaxFound) Name() string {
+	return KeyFaxFound
 }
 
-func (e *BotNotFounded) KafkaMessage() (kafka.Message, error) {
+func (e *FaxFound) KafkaMessage() (kafka.Message, error) {
+	km := NewClickKafkaMessage(e)
+
+	msg, err := json.Marshal(km)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Key:   []byte(e.Name()),
+		Value: msg,
+	}, nil
+}
+
+// CallAbandoned is a check ended by the call hanging up before the verdict.
+type CallAbandoned struct {
+	CallID     string `json:"id"`
+	Dest       string `json:"dnid"`
+	From       string `json:"from"`
+	Cause      int    `json:"cause"`
+	CauseTxt   string `json:"cause_txt"`
+	ListenedMs int64  `json:"listened_ms"`
+	Transcript string `json:"transcript"`
+	Policy     Policy `json:"policy"`
+	EventName  string `json:"event_name"`
+}
+
+func (e *CallAbandoned) Name() string {
+	return KeyCallAbandoned
+}
+
+func (e *CallAbandoned) KafkaMessage() (kafka.Message, error) {
 	km := NewClickKafkaMessage(e)
 
 	msg, err := json.Marshal(km)
@@ -16,6 +16,14 @@ func (s *Service) liveness() http.HandlerFunc {
 
 func (s *Service) readiness() http.HandlerFunc {
 	return func(w http.ResponseWriter, request *http.Request) {
+		if s.services.BotChecker != nil {
+			if err := s.services.BotChecker.Ready(); err != nil {
+				s.writer.WriteError(w, err, http.StatusServiceUnavailable)
+
+				return
+			}
+		}
+
 		s.writer.WriteSuccess(w, "OK", nil)
 	}
 }
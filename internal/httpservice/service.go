@@ -91,6 +91,13 @@ func (s *Service) configureRouter() {
 	s.router.Get("/liveness", s.liveness())
 
 	s.router.With(mwGroups.GetChain(KeyGroupBase)...).Handle("/bot-check/{uniqID}", s.services.BotChecker.CheckBotHandler())
+	s.router.With(mwGroups.GetChain(KeyGroupBase)...).Post("/bot-check/{uniqID}/ari", s.services.BotChecker.CheckMediaHandler())
+
+	s.router.With(mwGroups.GetChain(KeyGroupBase)...).Route("/api/sessions", func(r chi.Router) {
+		r.Get("/", s.services.BotChecker.SessionsHandler())
+		r.Get("/{id}", s.services.BotChecker.SessionHandler())
+		r.Delete("/{id}", s.services.BotChecker.CancelSessionHandler())
+	})
 }
 
 func WithHTTPAddress(address string) Configuration {
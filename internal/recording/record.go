@@ -0,0 +1,63 @@
+package recording
+
+import (
+	"encoding/binary"
+	"os"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+// Record is a wav file written as a stream, its header gets the real sizes on Close.
+type Record struct {
+	file *os.File
+	size uint32
+}
+
+func createRecord(path string, format audio.WavFormat) (*Record, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = file.Write(audio.WavHeader(format, audio.WavStreamDataSize)); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return &Record{file: file}, nil
+}
+
+func (r *Record) Write(p []byte) (int, error) {
+	n, err := r.file.Write(p)
+	r.size += uint32(n)
+
+	return n, err
+}
+
+// Discard closes the record and deletes its file, for a check that has not started.
+func (r *Record) Discard() error {
+	_ = r.file.Close()
+
+	return os.Remove(r.file.Name())
+}
+
+func (r *Record) Close() error {
+	var sizes [4]byte
+
+	binary.LittleEndian.PutUint32(sizes[:], r.size+audio.WavHeaderSize-8)
+	if _, err := r.file.WriteAt(sizes[:], 4); err != nil {
+		_ = r.file.Close()
+
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(sizes[:], r.size)
+	if _, err := r.file.WriteAt(sizes[:], audio.WavHeaderSize-4); err != nil {
+		_ = r.file.Close()
+
+		return err
+	}
+
+	return r.file.Close()
+}
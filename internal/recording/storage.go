@@ -0,0 +1,224 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/Arten331/observability/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	extWav  = ".wav"
+	extMeta = ".json"
+
+	defaultCleanupInterval = 10 * time.Minute
+)
+
+// Storage keeps call records in a directory, a wav file and a JSON sidecar per call,
+// and deletes the oldest of them by age and total size.
+type Storage struct {
+	dir             string
+	maxAge          time.Duration
+	maxSize         int64
+	cleanupInterval time.Duration
+}
+
+type Options struct {
+	Dir             string
+	MaxAge          time.Duration // zero keeps records of any age
+	MaxSize         int64         // bytes, zero does not limit the total size
+	CleanupInterval time.Duration
+}
+
+// Metadata is the sidecar of a call record.
+type Metadata struct {
+	CallID                 string    `json:"call_id"`
+	Caller                 string    `json:"caller"`
+	DNID                   string    `json:"dnid"`
+	StartedAt              time.Time `json:"started_at"`
+	IsBot                  bool      `json:"is_bot"`
+	Action                 string    `json:"action,omitempty"`
+	Source                 string    `json:"source,omitempty"`
+	Category               string    `json:"category,omitempty"`
+	Detail                 string    `json:"detail,omitempty"`
+	Phrase                 string    `json:"phrase,omitempty"`
+	Transcript             string    `json:"transcript"`
+	DurationMs             int64     `json:"duration_ms"`
+	GreetingMs             int64     `json:"greeting_ms"`
+	SilenceAfterGreetingMs int64     `json:"silence_after_greeting_ms"`
+}
+
+func New(o Options) (*Storage, error) {
+	if o.Dir == "" {
+		return nil, errors.New("recording storage require Dir")
+	}
+
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &Storage{
+		dir:             o.Dir,
+		maxAge:          o.MaxAge,
+		maxSize:         o.MaxSize,
+		cleanupInterval: o.CleanupInterval,
+	}
+
+	if s.cleanupInterval <= 0 {
+		s.cleanupInterval = defaultCleanupInterval
+	}
+
+	return s, nil
+}
+
+// Create starts the wav record of a call, an existing record of the call is replaced.
+func (s *Storage) Create(callID string, format audio.WavFormat) (*Record, error) {
+	return createRecord(s.path(callID, extWav), format)
+}
+
+// SaveMetadata writes the JSON sidecar of a call record.
+func (s *Storage) SaveMetadata(m Metadata) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path(m.CallID, extMeta), data, 0o644) //nolint:gosec // records are not secret
+}
+
+// Run deletes expired records every cleanup interval until ctx is done.
+func (s *Storage) Run(ctx context.Context, _ context.CancelFunc) {
+	ticker := time.NewTicker(s.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Cleanup(time.Now()); err != nil {
+			logger.L().Error("records cleanup failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// callFiles are the files of one call record.
+type callFiles struct {
+	paths   []string
+	size    int64
+	modTime time.Time
+}
+
+// Cleanup deletes records older than MaxAge, then the oldest ones until the total size fits MaxSize.
+func (s *Storage) Cleanup(now time.Time) error {
+	records, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+
+	kept := records[:0]
+
+	for _, r := range records {
+		if s.maxAge > 0 && now.Sub(r.modTime) > s.maxAge {
+			s.remove(r)
+
+			continue
+		}
+
+		total += r.size
+		kept = append(kept, r)
+	}
+
+	for _, r := range kept {
+		if s.maxSize <= 0 || total <= s.maxSize {
+			break
+		}
+
+		s.remove(r)
+		total -= r.size
+	}
+
+	return nil
+}
+
+// list returns call records from the oldest.
+func (s *Storage) list() ([]*callFiles, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byCall := map[string]*callFiles{}
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != extWav && ext != extMeta) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue // deleted meanwhile
+		}
+
+		id := strings.TrimSuffix(entry.Name(), ext)
+
+		r, ok := byCall[id]
+		if !ok {
+			r = &callFiles{}
+			byCall[id] = r
+		}
+
+		r.paths = append(r.paths, filepath.Join(s.dir, entry.Name()))
+		r.size += info.Size()
+
+		if info.ModTime().After(r.modTime) {
+			r.modTime = info.ModTime()
+		}
+	}
+
+	records := make([]*callFiles, 0, len(byCall))
+	for _, r := range byCall {
+		records = append(records, r)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].modTime.Before(records[j].modTime)
+	})
+
+	return records, nil
+}
+
+func (s *Storage) remove(r *callFiles) {
+	for _, path := range r.paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.L().Error("unable remove record", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+// path of a call file, the call id is reduced to characters safe in a file name.
+func (s *Storage) path(callID, ext string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, callID)
+
+	return filepath.Join(s.dir, name+ext)
+}
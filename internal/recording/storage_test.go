@@ -0,0 +1,117 @@
+//go:build test && !integration
+
+package recording_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/internal/recording"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+// newStorage creates storage in a directory missing yet.
+func newStorage(t *testing.T, o recording.Options) (*recording.Storage, string) {
+	t.Helper()
+
+	o.Dir = filepath.Join(t.TempDir(), "records")
+
+	s, err := recording.New(o)
+	require.NoError(t, err)
+
+	return s, o.Dir
+}
+
+func TestStorage_Record(t *testing.T) {
+	s, dir := newStorage(t, recording.Options{})
+	format := audio.WavFormat{SampleRate: 8000, Channels: 2, BitsPerSample: 16}
+
+	record, err := s.Create("1666.42/x", format)
+	require.NoError(t, err)
+
+	_, err = record.Write(make([]byte, 3200))
+	require.NoError(t, err)
+	require.NoError(t, record.Close())
+
+	err = s.SaveMetadata(recording.Metadata{CallID: "1666.42/x", Caller: "100", Transcript: "алло"})
+	require.NoError(t, err)
+
+	file, err := os.Open(filepath.Join(dir, "1666.42_x.wav"))
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	read, err := audio.ReadWavHeader(file)
+	require.NoError(t, err)
+	require.Equal(t, format, read)
+
+	expected := audio.WavHeader(format, 3200)
+	header := make([]byte, audio.WavHeaderSize)
+	_, err = file.ReadAt(header, 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, header)
+
+	data, err := os.ReadFile(filepath.Join(dir, "1666.42_x.json"))
+	require.NoError(t, err)
+
+	var meta recording.Metadata
+	require.NoError(t, json.Unmarshal(data, &meta))
+	require.Equal(t, "100", meta.Caller)
+	require.Equal(t, "алло", meta.Transcript)
+}
+
+func TestStorage_Discard(t *testing.T) {
+	s, dir := newStorage(t, recording.Options{})
+
+	record, err := s.Create("1666.42", audio.WavFormat{SampleRate: 8000, Channels: 1, BitsPerSample: 16})
+	require.NoError(t, err)
+	require.NoError(t, record.Discard())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestStorage_Cleanup(t *testing.T) {
+	s, dir := newStorage(t, recording.Options{MaxAge: time.Hour, MaxSize: 3000})
+	now := time.Now()
+
+	// every call takes about 1.3 KB: 1 KB of audio, the wav header and the sidecar
+	calls := map[string]time.Duration{
+		"expired": 2 * time.Hour,
+		"oldest":  50 * time.Minute,
+		"older":   40 * time.Minute,
+		"newest":  time.Minute,
+	}
+
+	for id, age := range calls {
+		record, err := s.Create(id, audio.WavFormat{SampleRate: 8000, Channels: 1, BitsPerSample: 16})
+		require.NoError(t, err)
+
+		_, err = record.Write(make([]byte, 1000))
+		require.NoError(t, err)
+		require.NoError(t, record.Close())
+		require.NoError(t, s.SaveMetadata(recording.Metadata{CallID: id}))
+
+		for _, ext := range []string{".wav", ".json"} {
+			path := filepath.Join(dir, id+ext)
+			require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+		}
+	}
+
+	require.NoError(t, s.Cleanup(now))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	require.ElementsMatch(t, []string{"newest.json", "newest.wav", "older.json", "older.wav"}, names)
+}
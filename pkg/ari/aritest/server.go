@@ -0,0 +1,566 @@
+// Package aritest provides an in-process ARI server for tests. It keeps channels and bridges
+// in memory, records every request and sends events over the events websocket.
+package aritest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ariclient "github.com/Arten331/bot-checker/pkg/ari"
+	"github.com/go-chi/chi/v5"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+)
+
+const (
+	User     = "aritest"
+	Password = "aritest"
+)
+
+// Request is an ARI call received by the server, Path is relative to /ari.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   map[string]interface{} // decoded JSON, nil without one
+}
+
+// Channel is the state of a channel known to the server.
+type Channel struct {
+	ID           string
+	Variables    map[string]string
+	HungUp       bool
+	HangupReason string
+	Continued    bool
+	Subscribed   bool // the application follows its events
+}
+
+// Server answers the ARI endpoints used by the checker: asterisk info and ping, channels with
+// their variables, hangup, continue, snoop and externalMedia, bridges, application subscriptions
+// and user events.
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	requests  []Request
+	channels  map[string]*Channel
+	bridges   map[string][]string
+	listeners map[net.Conn]struct{}
+	down      bool
+}
+
+func NewServer() *Server {
+	s := &Server{
+		channels:  make(map[string]*Channel),
+		bridges:   make(map[string][]string),
+		listeners: make(map[net.Conn]struct{}),
+	}
+
+	router := chi.NewRouter()
+	router.Use(s.record)
+	router.Route("/ari", func(r chi.Router) {
+		r.Get("/asterisk/info", s.info)
+		r.Get("/asterisk/ping", s.ping)
+		r.Get("/events", s.events)
+		r.Post("/events/user/{name}", noContent)
+		r.Post("/applications/{app}/subscription", s.subscribe)
+		r.Delete("/applications/{app}/subscription", s.unsubscribe)
+
+		r.Get("/channels", s.listChannels)
+		r.Post("/channels/externalMedia", s.externalMedia)
+		r.Get("/channels/{id}", s.withChannel(s.getChannel))
+		r.Delete("/channels/{id}", s.withChannel(s.hangup))
+		r.Get("/channels/{id}/variable", s.withChannel(s.getVariable))
+		r.Post("/channels/{id}/variable", s.withChannel(s.setVariable))
+		r.Post("/channels/{id}/continue", s.withChannel(s.continueDialplan))
+		r.Post("/channels/{id}/snoop/{snoopID}", s.withChannel(s.snoop))
+
+		r.Post("/bridges/{id}", s.createBridge)
+		r.Post("/bridges/{id}/addChannel", s.addToBridge)
+		r.Delete("/bridges/{id}", s.deleteBridge)
+	})
+
+	s.server = httptest.NewServer(router)
+
+	return s
+}
+
+func (s *Server) Close() {
+	s.mu.Lock()
+	for conn := range s.listeners {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.server.Close()
+}
+
+// Options connect a client of pkg/ari to the server.
+func (s *Server) Options() ariclient.Options {
+	addr := s.server.Listener.Addr().(*net.TCPAddr)
+
+	return ariclient.Options{
+		Host:         addr.IP.String(),
+		Port:         addr.Port,
+		User:         User,
+		Password:     Password,
+		PingInterval: 50 * time.Millisecond,
+		MinBackoff:   10 * time.Millisecond,
+		MaxBackoff:   100 * time.Millisecond,
+	}
+}
+
+// AddChannel makes a channel known to the server.
+func (s *Server) AddChannel(id string, variables map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addChannel(id, variables)
+}
+
+func (s *Server) addChannel(id string, variables map[string]string) *Channel {
+	ch := &Channel{ID: id, Variables: make(map[string]string)}
+
+	for name, value := range variables {
+		ch.Variables[name] = value
+	}
+
+	s.channels[id] = ch
+
+	return ch
+}
+
+// Channel returns a copy of the channel state.
+func (s *Server) Channel(id string) (Channel, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.channels[id]
+	if !ok {
+		return Channel{}, false
+	}
+
+	c := *ch
+	c.Variables = make(map[string]string, len(ch.Variables))
+
+	for name, value := range ch.Variables {
+		c.Variables[name] = value
+	}
+
+	return c, true
+}
+
+// Requests returns the calls received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Calls lists the received calls as "METHOD /path", handy to compare with the expected ones.
+func (s *Server) Calls() []string {
+	requests := s.Requests()
+	calls := make([]string, 0, len(requests))
+
+	for _, r := range requests {
+		calls = append(calls, r.Method+" "+r.Path)
+	}
+
+	return calls
+}
+
+// SetDown makes the REST API fail and drops the events websockets, as an unreachable asterisk.
+func (s *Server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.down = down
+
+	if down {
+		for conn := range s.listeners {
+			_ = conn.Close()
+		}
+	}
+}
+
+// Listeners is the count of open events websockets.
+func (s *Server) Listeners() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.listeners)
+}
+
+// Emit sends an event to every events websocket, typ is set as its type.
+func (s *Server) Emit(typ string, event map[string]interface{}) {
+	message := map[string]interface{}{
+		"type":        typ,
+		"application": "bot_checker",
+		"timestamp":   time.Now().Format("2006-01-02T15:04:05.000-0700"),
+	}
+
+	for k, v := range event {
+		message[k] = v
+	}
+
+	payload, _ := json.Marshal(message)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.listeners {
+		_ = wsutil.WriteServerText(conn, payload)
+	}
+}
+
+// Hangup ends a channel from the far side, as a caller hanging up.
+func (s *Server) Hangup(id string, cause int, causeTxt string) {
+	s.mu.Lock()
+	if ch, ok := s.channels[id]; ok {
+		ch.HungUp = true
+	}
+	s.mu.Unlock()
+
+	s.Emit("ChannelHangupRequest", map[string]interface{}{
+		"channel": channelData(id),
+		"cause":   cause,
+	})
+	s.Emit("ChannelDestroyed", map[string]interface{}{
+		"channel":   channelData(id),
+		"cause":     cause,
+		"cause_txt": causeTxt,
+	})
+}
+
+// StasisStart announces a known channel entering the application.
+func (s *Server) StasisStart(id string) {
+	s.Emit("StasisStart", map[string]interface{}{"channel": channelData(id)})
+}
+
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := Request{
+			Method: r.Method,
+			Path:   strings.TrimPrefix(r.URL.Path, "/ari"),
+			Query:  r.URL.Query(),
+		}
+
+		if r.Body != nil {
+			payload, _ := io.ReadAll(r.Body)
+			if len(payload) > 0 {
+				_ = json.Unmarshal(payload, &req.Body)
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(payload))
+		}
+
+		if user, password, _ := r.BasicAuth(); user != User || password != Password {
+			writeError(w, http.StatusUnauthorized, "Authentication required")
+
+			return
+		}
+
+		s.mu.Lock()
+		down := s.down
+
+		if req.Path != "/asterisk/ping" {
+			s.requests = append(s.requests, req)
+		}
+		s.mu.Unlock()
+
+		if down {
+			writeError(w, http.StatusServiceUnavailable, "Asterisk is down")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) info(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"system": map[string]interface{}{"version": "18.0.0", "entity_id": "aritest"},
+	})
+}
+
+func (s *Server) ping(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"asterisk_id": "aritest",
+		"ping":        "pong",
+		"timestamp":   time.Now().Format("2006-01-02T15:04:05.000-0700"),
+	})
+}
+
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	conn, _, _, err := ws.UpgradeHTTP(r, w)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.listeners[conn] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, conn)
+		s.mu.Unlock()
+
+		_ = conn.Close()
+	}()
+
+	// control replies share the connection with Emit
+	controlHandler := wsutil.ControlFrameHandler(conn, ws.StateServerSide)
+	reader := &wsutil.Reader{Source: conn, State: ws.StateServerSide}
+
+	for {
+		header, err := reader.NextFrame()
+		if err != nil {
+			return
+		}
+
+		if header.OpCode.IsControl() {
+			s.mu.Lock()
+			err = controlHandler(header, reader)
+			s.mu.Unlock()
+		} else {
+			err = reader.Discard()
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) listChannels(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	list := make([]map[string]interface{}, 0, len(s.channels))
+
+	for id, ch := range s.channels {
+		if !ch.HungUp {
+			data := channelData(id)
+			// as if every variable were named by channelvars
+			data["channelvars"] = ch.Variables
+			list = append(list, data)
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, list)
+}
+
+func (s *Server) externalMedia(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("app") == "" || query.Get("external_host") == "" || query.Get("format") == "" {
+		writeError(w, http.StatusBadRequest, "app, external_host and format are required")
+
+		return
+	}
+
+	id := query.Get("channelId")
+
+	s.mu.Lock()
+	if id == "" {
+		id = "media-" + strconv.Itoa(len(s.channels)+1)
+	}
+
+	s.addChannel(id, map[string]string{"UNICASTRTP_LOCAL_ADDRESS": "127.0.0.1"})
+	s.mu.Unlock()
+
+	writeJSON(w, channelData(id))
+}
+
+// withChannel passes the channel of the path to h, unknown or hung up ones are not found.
+func (s *Server) withChannel(h func(w http.ResponseWriter, r *http.Request, ch *Channel)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		ch, ok := s.channels[chi.URLParam(r, "id")]
+		if !ok || ch.HungUp {
+			writeError(w, http.StatusNotFound, "Channel not found")
+
+			return
+		}
+
+		h(w, r, ch)
+	}
+}
+
+func (s *Server) getChannel(w http.ResponseWriter, _ *http.Request, ch *Channel) {
+	writeJSON(w, channelData(ch.ID))
+}
+
+func (s *Server) hangup(w http.ResponseWriter, r *http.Request, ch *Channel) {
+	ch.HungUp = true
+	ch.HangupReason = r.URL.Query().Get("reason")
+
+	w.WriteHeader(http.StatusNoContent)
+
+	go s.Emit("ChannelDestroyed", map[string]interface{}{
+		"channel":   channelData(ch.ID),
+		"cause":     16,
+		"cause_txt": "Normal Clearing",
+	})
+}
+
+func (s *Server) getVariable(w http.ResponseWriter, r *http.Request, ch *Channel) {
+	writeJSON(w, map[string]string{"value": ch.Variables[r.URL.Query().Get("variable")]})
+}
+
+func (s *Server) setVariable(w http.ResponseWriter, r *http.Request, ch *Channel) {
+	body := decodeBody(r)
+
+	name, _ := body["variable"].(string)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "variable is required")
+
+		return
+	}
+
+	value, _ := body["value"].(string)
+	ch.Variables[name] = value
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) continueDialplan(w http.ResponseWriter, _ *http.Request, ch *Channel) {
+	ch.Continued = true
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) snoop(w http.ResponseWriter, r *http.Request, _ *Channel) {
+	id := chi.URLParam(r, "snoopID")
+	s.addChannel(id, nil)
+
+	writeJSON(w, channelData(id))
+}
+
+func (s *Server) createBridge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	s.mu.Lock()
+	s.bridges[id] = nil
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{"id": id, "bridge_type": "mixing", "channels": []string{}})
+}
+
+func (s *Server) addToBridge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	channelID, _ := decodeBody(r)["channel"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channels, ok := s.bridges[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Bridge not found")
+
+		return
+	}
+
+	if ch, ok := s.channels[channelID]; !ok || ch.HungUp {
+		writeError(w, http.StatusBadRequest, "Channel not found")
+
+		return
+	}
+
+	s.bridges[id] = append(channels, channelID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteBridge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bridges[id]; !ok {
+		writeError(w, http.StatusNotFound, "Bridge not found")
+
+		return
+	}
+
+	delete(s.bridges, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) subscribe(w http.ResponseWriter, r *http.Request) {
+	source, _ := decodeBody(r)["eventSource"].(string)
+	if source == "" {
+		source = r.URL.Query().Get("eventSource")
+	}
+
+	s.setSubscribed(w, source, true)
+}
+
+func (s *Server) unsubscribe(w http.ResponseWriter, r *http.Request) {
+	s.setSubscribed(w, r.URL.Query().Get("eventSource"), false)
+}
+
+// setSubscribed follows the channel events of source, only channel sources are known.
+func (s *Server) setSubscribed(w http.ResponseWriter, source string, subscribed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.channels[strings.TrimPrefix(source, "channel:")]
+	if !strings.HasPrefix(source, "channel:") || !ok || ch.HungUp {
+		writeError(w, http.StatusUnprocessableEntity, "Event source does not exist")
+
+		return
+	}
+
+	ch.Subscribed = subscribed
+
+	writeJSON(w, map[string]interface{}{"name": "bot_checker"})
+}
+
+func noContent(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeBody reads a JSON object body, nil without one.
+func decodeBody(r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	return body
+}
+
+func channelData(id string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":    id,
+		"name":  fmt.Sprintf("PJSIP/aritest-%s", id),
+		"state": "Up",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers as asterisk does, with a JSON message.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
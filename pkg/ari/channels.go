@@ -0,0 +1,28 @@
+package ari
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/CyCoreSystems/ari"
+)
+
+// ListChannels lists the channels of the asterisk with their data,
+// ChannelVars holds the variables named by channelvars of ari.conf.
+func (c *Client) ListChannels(ctx context.Context) ([]ari.ChannelData, error) {
+	resp, err := c.get(ctx, "/channels")
+	if err != nil {
+		return nil, fmt.Errorf("list channels: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var channels []ari.ChannelData
+
+	err = json.NewDecoder(resp.Body).Decode(&channels)
+	if err != nil {
+		return nil, fmt.Errorf("list channels: %w", err)
+	}
+
+	return channels, nil
+}
@@ -2,10 +2,14 @@ package ari
 
 import (
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/Arten331/observability/logger"
 	"github.com/CyCoreSystems/ari"
 	"github.com/CyCoreSystems/ari/client/native"
+	"github.com/CyCoreSystems/ari/stdbus"
 	"github.com/inconshreveable/log15"
 )
 
@@ -16,9 +20,34 @@ type Options struct {
 	Password string
 	Original string
 	Secure   bool
+
+	PingInterval time.Duration // of the REST API and the events websocket
+	MinBackoff   time.Duration // first delay of reconnects, doubled up to MaxBackoff
+	MaxBackoff   time.Duration
 }
 
-func New(o Options) ari.Client {
+// Client is the ARI client with the calls missing in the ari library.
+// Its events websocket is kept by Run, events are received on the Bus.
+type Client struct {
+	ari.Client
+
+	url      string
+	wsURL    string
+	origin   string
+	user     string
+	password string
+	http     *http.Client
+	bus      ari.Bus
+
+	pingInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	mu    sync.Mutex
+	state connState
+}
+
+func New(o Options) *Client {
 	wsProto := "ws"
 	httpProto := "http"
 
@@ -44,9 +73,42 @@ func New(o Options) ari.Client {
 		Password:        o.Password,
 	})
 
-	logger.L().Info("ari client connected")
+	c := &Client{
+		Client:       cl,
+		url:          url,
+		wsURL:        wsURL,
+		origin:       o.Original,
+		user:         o.User,
+		password:     o.Password,
+		http:         &http.Client{Timeout: requestTimeout},
+		bus:          stdbus.New(),
+		pingInterval: o.PingInterval,
+		minBackoff:   o.MinBackoff,
+		maxBackoff:   o.MaxBackoff,
+	}
+
+	if c.pingInterval <= 0 {
+		c.pingInterval = defaultPingInterval
+	}
+
+	if c.minBackoff <= 0 {
+		c.minBackoff = defaultMinBackoff
+	}
+
+	if c.maxBackoff <= 0 {
+		c.maxBackoff = defaultMaxBackoff
+	}
+
+	if c.maxBackoff < c.minBackoff {
+		c.maxBackoff = c.minBackoff
+	}
+
+	return c
+}
 
-	return cl
+// Bus returns the events received by the websocket of Run.
+func (c *Client) Bus() ari.Bus {
+	return c.bus
 }
 
 type loggerWrapper struct{}
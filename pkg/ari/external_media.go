@@ -0,0 +1,52 @@
+package ari
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/CyCoreSystems/ari"
+)
+
+// ExternalMediaOptions describes a channel sending the media of its bridge to an external host.
+type ExternalMediaOptions struct {
+	ChannelID    string
+	App          string
+	ExternalHost string // host:port receiving RTP
+	Format       string // asterisk codec name, ulaw or slin16
+	Direction    string // defaults to both
+}
+
+// ExternalMedia creates an externalMedia channel with RTP over UDP.
+func (c *Client) ExternalMedia(ctx context.Context, o ExternalMediaOptions) (*ari.ChannelHandle, error) {
+	query := url.Values{}
+	query.Set("app", o.App)
+	query.Set("external_host", o.ExternalHost)
+	query.Set("format", o.Format)
+	query.Set("encapsulation", "rtp")
+	query.Set("transport", "udp")
+
+	if o.ChannelID != "" {
+		query.Set("channelId", o.ChannelID)
+	}
+
+	if o.Direction != "" {
+		query.Set("direction", o.Direction)
+	}
+
+	resp, err := c.post(ctx, "/channels/externalMedia", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("externalMedia: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var data ari.ChannelData
+
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("externalMedia: %w", err)
+	}
+
+	return c.Channel().Get(ari.NewKey(ari.ChannelKey, data.ID)), nil
+}
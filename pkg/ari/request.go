@@ -0,0 +1,66 @@
+package ari
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const requestTimeout = 10 * time.Second
+
+// post calls the ARI REST API, body is sent as JSON unless nil.
+// A response other than 2xx is an error, the caller closes the body of a successful one.
+func (c *Client) post(ctx context.Context, path string, query url.Values, body interface{}) (*http.Response, error) {
+	return c.do(ctx, http.MethodPost, path, query, body)
+}
+
+func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, path, nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reqBody = bytes.NewReader(payload)
+	}
+
+	reqURL := c.url + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,256 @@
+package ari
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Arten331/observability/logger"
+	"github.com/CyCoreSystems/ari"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPingInterval = 5 * time.Second
+	defaultMinBackoff   = 500 * time.Millisecond
+	defaultMaxBackoff   = 30 * time.Second
+)
+
+// connState is what is known about asterisk, it is reachable when both are up.
+type connState struct {
+	events bool // the events websocket is open
+	rest   bool // the last REST ping succeeded
+}
+
+func (s connState) up() bool {
+	return s.events && s.rest
+}
+
+// Connected tells whether asterisk answers the REST API and sends events.
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state.up()
+}
+
+func (c *Client) setState(update func(s *connState)) {
+	c.mu.Lock()
+	was := c.state.up()
+	update(&c.state)
+	now := c.state.up()
+	c.mu.Unlock()
+
+	switch {
+	case now && !was:
+		logger.L().Info("ARI connected", zap.String("url", c.url))
+	case was && !now:
+		logger.L().Warn("ARI disconnected", zap.String("url", c.url))
+	}
+}
+
+// Run keeps the events websocket open and pings the REST API until ctx is done.
+// A lost websocket is reconnected with an exponential backoff.
+func (c *Client) Run(ctx context.Context, _ context.CancelFunc) {
+	go c.pingREST(ctx)
+
+	backoff := c.minBackoff
+
+	for {
+		opened, err := c.serveEvents(ctx)
+
+		c.setState(func(s *connState) { s.events = false })
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if opened {
+			backoff = c.minBackoff
+		}
+
+		logger.L().Warn("ARI events websocket lost", zap.Duration("retry", backoff), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = nextBackoff(backoff, c.maxBackoff)
+	}
+}
+
+func nextBackoff(backoff, limit time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > limit {
+		return limit
+	}
+
+	return backoff
+}
+
+func (c *Client) pingREST(ctx context.Context) {
+	ticker := time.NewTicker(c.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		err := c.ping(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.L().Debug("ARI ping failed", zap.Error(err))
+		}
+
+		c.setState(func(s *connState) { s.rest = err == nil })
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) ping(ctx context.Context) error {
+	resp, err := c.get(ctx, "/asterisk/ping")
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// serveEvents passes the events of the application to the bus until the websocket fails,
+// opened tells whether it was established.
+func (c *Client) serveEvents(ctx context.Context) (opened bool, err error) {
+	header := http.Header{}
+	header.Set("Origin", c.origin)
+
+	if c.user != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.user+":"+c.password)))
+	}
+
+	dialer := ws.Dialer{
+		Header:  ws.HandshakeHeaderHTTP(header),
+		Timeout: requestTimeout,
+	}
+
+	conn, br, _, err := dialer.Dial(ctx, c.wsURL+"?app="+url.QueryEscape(c.ApplicationName()))
+	if err != nil {
+		return false, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		_ = conn.Close()
+	}()
+
+	ec := &eventsConn{Conn: conn, r: conn}
+	if br != nil {
+		// frames sent right after the handshake
+		ec.r = io.MultiReader(br, conn)
+	}
+
+	go ec.ping(done, c.pingInterval)
+
+	c.setState(func(s *connState) { s.events = true })
+
+	return true, ec.read(c.bus, 2*c.pingInterval)
+}
+
+// eventsConn writes whole frames one at a time, pings and control replies share the connection.
+type eventsConn struct {
+	net.Conn
+
+	r  io.Reader
+	mu sync.Mutex
+}
+
+func (c *eventsConn) ping(done <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		_ = c.SetWriteDeadline(time.Now().Add(interval))
+		err := wsutil.WriteClientMessage(c.Conn, ws.OpPing, nil)
+		c.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// read decodes the events, the websocket is considered lost without any frame within timeout.
+func (c *eventsConn) read(bus ari.Bus, timeout time.Duration) error {
+	controlHandler := wsutil.ControlFrameHandler(c.Conn, ws.StateClientSide)
+	control := func(header ws.Header, r io.Reader) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return controlHandler(header, r)
+	}
+
+	reader := &wsutil.Reader{
+		Source:         c.r,
+		State:          ws.StateClientSide,
+		CheckUTF8:      true,
+		OnIntermediate: control,
+	}
+
+	for {
+		_ = c.SetReadDeadline(time.Now().Add(timeout))
+
+		header, err := reader.NextFrame()
+		if err != nil {
+			return err
+		}
+
+		if header.OpCode.IsControl() {
+			if err = control(header, reader); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		if header.OpCode != ws.OpText {
+			return errors.New("unexpected binary frame")
+		}
+
+		e, err := ari.DecodeEvent(data)
+		if err != nil {
+			logger.L().Debug("unable decode ARI event", zap.Error(err))
+
+			continue
+		}
+
+		bus.Send(e)
+	}
+}
@@ -0,0 +1,112 @@
+//go:build test && !integration
+
+package ari
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CyCoreSystems/ari"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextBackoff(t *testing.T) {
+	require.Equal(t, time.Second, nextBackoff(500*time.Millisecond, 30*time.Second))
+	require.Equal(t, 30*time.Second, nextBackoff(20*time.Second, 30*time.Second))
+}
+
+func TestClientRun(t *testing.T) {
+	var pingOK int32 = 1
+
+	closeEvents := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ari/asterisk/ping", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&pingOK) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if user, password, _ := r.BasicAuth(); user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	mux.HandleFunc("/ari/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("app") != "bot_checker" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		conn, _, _, err := ws.UpgradeHTTP(r, w)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		event := `{"type":"StasisStart","application":"bot_checker","channel":{"id":"1650000000.1"}}`
+		_ = wsutil.WriteServerText(conn, []byte(event))
+
+		<-closeEvents
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	c := New(Options{
+		Host:         host,
+		Port:         portNum,
+		User:         "user",
+		Password:     "secret",
+		PingInterval: 20 * time.Millisecond,
+		MinBackoff:   10 * time.Millisecond,
+		MaxBackoff:   20 * time.Millisecond,
+	})
+
+	sub := c.Bus().Subscribe(nil, ari.Events.StasisStart)
+	defer sub.Cancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go c.Run(ctx, cancel)
+
+	select {
+	case e := <-sub.Events():
+		require.Equal(t, "1650000000.1", e.(*ari.StasisStart).Channel.ID)
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	require.Eventually(t, c.Connected, time.Second, 5*time.Millisecond)
+
+	atomic.StoreInt32(&pingOK, 0)
+	require.Eventually(t, func() bool { return !c.Connected() }, time.Second, 5*time.Millisecond)
+
+	atomic.StoreInt32(&pingOK, 1)
+	require.Eventually(t, c.Connected, time.Second, 5*time.Millisecond)
+
+	// the websocket is reopened after it is lost
+	close(closeEvents)
+
+	select {
+	case <-sub.Events():
+	case <-time.After(time.Second):
+		t.Fatal("events websocket not reconnected")
+	}
+}
@@ -0,0 +1,24 @@
+package ari
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// UserEvent raises a user event of the application with the channel as its source,
+// ARI applications receive it as ChannelUserevent and AMI as UserEvent.
+func (c *Client) UserEvent(ctx context.Context, name, channelID string, variables map[string]string) error {
+	query := url.Values{}
+	query.Set("application", c.ApplicationName())
+	query.Set("source", "channel:"+channelID)
+
+	resp, err := c.post(ctx, "/events/user/"+url.PathEscape(name), query, struct {
+		Variables map[string]string `json:"variables"`
+	}{variables})
+	if err != nil {
+		return fmt.Errorf("user event: %w", err)
+	}
+
+	return resp.Body.Close()
+}
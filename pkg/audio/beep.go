@@ -0,0 +1,42 @@
+package audio
+
+import (
+	"strconv"
+	"time"
+)
+
+const EventBeep EventType = "beep"
+
+type BeepOptions struct {
+	Frequency   float64
+	MinDuration time.Duration
+	ThresholdDB float64
+}
+
+// BeepDetector reports the answering machine beep, a pure tone lasting at least MinDuration.
+type BeepDetector struct {
+	options BeepOptions
+	tone    toneTracker
+}
+
+func NewBeepDetector(sampleRate int, o BeepOptions) *BeepDetector {
+	return &BeepDetector{
+		options: o,
+		tone:    newToneTracker(o.Frequency, sampleRate, o.ThresholdDB),
+	}
+}
+
+func (b *BeepDetector) Detect(frame []int16, at time.Duration) (Event, bool) {
+	if !b.tone.track(frame, at) || b.tone.reported || b.tone.length < b.options.MinDuration {
+		return Event{}, false
+	}
+
+	b.tone.reported = true
+
+	return Event{
+		Type:     EventBeep,
+		At:       b.tone.start,
+		Duration: b.tone.length,
+		Label:    strconv.FormatFloat(b.options.Frequency, 'f', -1, 64) + "Hz",
+	}, true
+}
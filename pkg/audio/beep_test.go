@@ -0,0 +1,65 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+var testBeep = audio.BeepOptions{
+	Frequency:   1000,
+	MinDuration: 200 * time.Millisecond,
+	ThresholdDB: -40,
+}
+
+// addTone mixes a tone of freq and amplitude into samples between the given offsets.
+func addTone(samples []int16, freq, amplitude float64, from, to time.Duration) {
+	for i := int(from.Seconds() * testSampleRate); i < int(to.Seconds()*testSampleRate); i++ {
+		samples[i] += int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/testSampleRate))
+	}
+}
+
+func TestToneMeasure(t *testing.T) {
+	samples := make([]int16, audio.FrameSamples(testSampleRate))
+	addTone(samples, 1000, math.MaxInt16/2, 0, audio.FrameDuration)
+
+	purity, level := audio.ToneMeasure(samples, 1000, testSampleRate)
+	require.InDelta(t, 1, purity, 0.01)
+	require.InDelta(t, -9, level, 0.5) // half scale sine: -6 dB peak, -9 dB rms
+
+	purity, _ = audio.ToneMeasure(samples, 1400, testSampleRate)
+	require.Less(t, purity, 0.01)
+}
+
+func TestBeepDetector(t *testing.T) {
+	samples := synth(3*time.Second, [2]time.Duration{200 * time.Millisecond, 1200 * time.Millisecond})
+	addTone(samples, 1000, 4000, 1500*time.Millisecond, 1900*time.Millisecond)
+
+	events := detectAll(audio.NewBeepDetector(testSampleRate, testBeep), samples)
+	require.Len(t, events, 1)
+
+	require.Equal(t, audio.EventBeep, events[0].Type)
+	require.Equal(t, "1000Hz", events[0].Label)
+	require.InDelta(t, 1500*time.Millisecond, events[0].At, float64(audio.FrameDuration))
+	require.InDelta(t, testBeep.MinDuration, events[0].Duration, float64(audio.FrameDuration))
+}
+
+func TestBeepDetector_Short(t *testing.T) {
+	samples := synth(time.Second)
+	addTone(samples, 1000, 4000, 200*time.Millisecond, 300*time.Millisecond)
+	addTone(samples, 1000, 4000, 500*time.Millisecond, 600*time.Millisecond)
+
+	require.Empty(t, detectAll(audio.NewBeepDetector(testSampleRate, testBeep), samples))
+}
+
+func TestBeepDetector_Records(t *testing.T) {
+	for _, name := range []string{"ABONENT_NE_MOJZHET.wav", "BLOCKED.wav", "DISCONNECTED1.wav", "BUSY_WAITING.wav", "SUBSCRIBER_NOT_AVAIL.wav"} {
+		events := detectAll(audio.NewBeepDetector(testSampleRate, testBeep), readRecord(t, name))
+		require.Empty(t, events, name)
+	}
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+const (
+	eventsBuffer  = 64
+	gatePreroll   = 15 // frames replayed when a closed gate opens again
+	bytesPerFrame = 2
+)
+
+// Analyze passes raw signed 16-bit mono pcm through unchanged and runs detectors on it.
+// Detector events are sent to Events, which is closed when the stage stops.
+// If a detector is an audio.Gate, frames are dropped while every gate is closed.
+type Analyze struct {
+	audio.Stream
+
+	sampleRate int
+	detectors  []audio.Detector
+	gates      []audio.Gate
+	events     chan audio.Event
+
+	mu       sync.Mutex
+	position time.Duration
+}
+
+func NewAnalyze(sampleRate int, detectors ...audio.Detector) *Analyze {
+	a := &Analyze{
+		sampleRate: sampleRate,
+		detectors:  detectors,
+		events:     make(chan audio.Event, eventsBuffer),
+	}
+
+	for _, d := range detectors {
+		if gate, ok := d.(audio.Gate); ok {
+			a.gates = append(a.gates, gate)
+		}
+	}
+
+	return a
+}
+
+func (a *Analyze) Name() string {
+	return "analyze"
+}
+
+func (a *Analyze) Handle(ctx context.Context) (in io.WriteCloser, out io.Reader, err error) {
+	in, out = a.Start(ctx, func(r io.Reader, w io.Writer) error {
+		defer close(a.events)
+
+		return a.analyze(ctx, r, w)
+	})
+
+	return in, out, nil
+}
+
+func (a *Analyze) Events() <-chan audio.Event {
+	return a.events
+}
+
+// Position returns the duration of audio analysed so far.
+func (a *Analyze) Position() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.position
+}
+
+func (a *Analyze) analyze(ctx context.Context, r io.Reader, w io.Writer) error {
+	frameBytes := audio.FrameSamples(a.sampleRate) * bytesPerFrame
+	frame := make([]int16, frameBytes/bytesPerFrame)
+	buf := make([]byte, audio.BUFFSIZE)
+	preroll := make([][]byte, 0, gatePreroll)
+
+	var (
+		pending []byte
+		at      time.Duration
+		open    = true
+	)
+
+	for {
+		n, errRead := r.Read(buf)
+		pending = append(pending, buf[:n]...)
+
+		offset := 0
+
+		for ; len(pending)-offset >= frameBytes; offset += frameBytes {
+			raw := pending[offset : offset+frameBytes]
+
+			for i := range frame {
+				frame[i] = int16(binary.LittleEndian.Uint16(raw[i*bytesPerFrame:]))
+			}
+
+			for _, d := range a.detectors {
+				if ev, ok := d.Detect(frame, at); ok {
+					select {
+					case a.events <- ev:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+
+			at += audio.FrameDuration
+
+			a.mu.Lock()
+			a.position = at
+			a.mu.Unlock()
+
+			open = a.gateOpen()
+			if !open {
+				if len(preroll) == gatePreroll {
+					preroll = append(preroll[:0], preroll[1:]...)
+				}
+
+				preroll = append(preroll, append([]byte(nil), raw...))
+
+				continue
+			}
+
+			for _, held := range preroll {
+				if _, err := w.Write(held); err != nil {
+					return err
+				}
+			}
+
+			preroll = preroll[:0]
+
+			if _, err := w.Write(raw); err != nil {
+				return err
+			}
+		}
+
+		pending = append(pending[:0], pending[offset:]...)
+
+		if errRead == io.EOF {
+			if open && len(pending) > 0 {
+				_, err := w.Write(pending)
+
+				return err
+			}
+
+			return nil
+		}
+
+		if errRead != nil {
+			return errRead
+		}
+	}
+}
+
+func (a *Analyze) gateOpen() bool {
+	if len(a.gates) == 0 {
+		return true
+	}
+
+	for _, g := range a.gates {
+		if g.Open() {
+			return true
+		}
+	}
+
+	return false
+}
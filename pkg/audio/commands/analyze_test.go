@@ -0,0 +1,84 @@
+//go:build test && !integration
+
+package commands
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+type countDetector struct {
+	frames int
+}
+
+func (c *countDetector) Detect(frame []int16, at time.Duration) (audio.Event, bool) {
+	c.frames++
+
+	if at == time.Second {
+		return audio.Event{Type: "second", At: at}, true
+	}
+
+	return audio.Event{}, false
+}
+
+func TestAnalyze_Passthrough(t *testing.T) {
+	input := make([]byte, 2*8000*2+7)
+	for i := range input {
+		input[i] = byte(i)
+	}
+
+	detector := &countDetector{}
+	analyze := NewAnalyze(8000, detector)
+
+	var events []audio.Event
+
+	done := make(chan struct{})
+
+	go func() {
+		for ev := range analyze.Events() {
+			events = append(events, ev)
+		}
+
+		close(done)
+	}()
+
+	result := runNativeCommand(t, analyze, input)
+	<-done
+
+	require.Equal(t, input, result)
+	require.Equal(t, 100, detector.frames)
+	require.Equal(t, []audio.Event{{Type: "second", At: time.Second}}, events)
+	require.Equal(t, 2*time.Second, analyze.Position())
+}
+
+func TestAnalyze_Gate(t *testing.T) {
+	// 3 s of silence, 1 s of tone
+	samples := make([]int16, 4*8000)
+	for i := 3 * 8000; i < len(samples); i++ {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*300*float64(i)/8000))
+	}
+
+	input := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(input[2*i:], uint16(s))
+	}
+
+	analyze := NewAnalyze(8000, audio.NewVAD(audio.VADOptions{SkipSilenceAfter: time.Second}))
+
+	go func() {
+		for range analyze.Events() {
+		}
+	}()
+
+	result := runNativeCommand(t, analyze, input)
+
+	// 1 s of silence before the gate closes, preroll before the tone and the tone itself
+	expected := 2 * (8000 + gatePreroll*audio.FrameSamples(8000) + 8000)
+	require.InDelta(t, expected, len(result), float64(4*audio.FrameSamples(8000)))
+	require.Equal(t, input[len(input)-8000:], result[len(result)-8000:])
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+const (
+	BackendSox    = "sox"
+	BackendNative = "native"
+)
+
+// NewWavPipeline returns the raw mono pcm at sampleRate to silence stripped wav chain for the backend.
+func NewWavPipeline(backend string, sampleRate int) ([]audio.PipeCommand, error) {
+	switch backend {
+	case "", BackendSox:
+		return []audio.PipeCommand{
+			NewPcmRawToWav(sampleRate),
+			NewPcmWavSilence(),
+		}, nil
+	case BackendNative:
+		return []audio.PipeCommand{
+			NewPcmRawToWavNative(sampleRate),
+			NewPcmWavSilenceNative(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown audio backend %q", backend)
+	}
+}
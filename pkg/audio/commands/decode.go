@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+// ChannelMix selects all channels of the input, mixed down.
+const ChannelMix = -1
+
+// Decode converts raw input of any supported audio.Format to signed 16-bit mono pcm
+// at sampleRate. Only the selected channel is kept, ChannelMix or a channel missing
+// in the input mixes all of them down.
+type Decode struct {
+	audio.Stream
+
+	format     audio.Format
+	sampleRate int
+	channel    int
+	record     io.WriteCloser
+}
+
+func NewDecode(format audio.Format, sampleRate, channel int) *Decode {
+	return &Decode{
+		format:     format,
+		sampleRate: sampleRate,
+		channel:    channel,
+	}
+}
+
+// Record sets w to receive the decoded input with every channel at the input rate.
+// w is closed when the stage stops, a failed write stops the recording only.
+func (d *Decode) Record(w io.WriteCloser) {
+	d.record = w
+}
+
+func (d *Decode) Name() string {
+	return "decode " + d.format.String()
+}
+
+func (d *Decode) Handle(ctx context.Context) (in io.WriteCloser, out io.Reader, err error) {
+	if err = d.format.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	in, out = d.Start(ctx, func(r io.Reader, w io.Writer) error {
+		if d.record != nil {
+			defer d.record.Close()
+		}
+
+		return d.decode(r, w)
+	})
+
+	return in, out, nil
+}
+
+func (d *Decode) decode(r io.Reader, w io.Writer) error {
+	sampleSize := d.format.SampleSize()
+	frameSize := sampleSize * d.format.Channels
+	resampler := audio.NewResampler(d.format.SampleRate, d.sampleRate)
+	record := d.record
+
+	buf := make([]byte, audio.BUFFSIZE)
+
+	var (
+		pending   []byte
+		frame     = make([]int16, d.format.Channels)
+		mono      []int16
+		samples   []int16
+		outBuf    []byte
+		recordBuf []byte
+	)
+
+	for {
+		n, errRead := r.Read(buf)
+		data := append(pending, buf[:n]...)
+
+		mono = mono[:0]
+		recordBuf = recordBuf[:0]
+
+		for ; len(data) >= frameSize; data = data[frameSize:] {
+			for c := range frame {
+				frame[c] = d.format.Decode(data[c*sampleSize:])
+			}
+
+			if record != nil {
+				recordBuf = appendSamples(recordBuf, frame)
+			}
+
+			mono = append(mono, d.pick(frame))
+		}
+
+		pending = append(pending[:0], data...)
+
+		if len(recordBuf) > 0 {
+			if _, err := record.Write(recordBuf); err != nil {
+				record = nil
+			}
+		}
+
+		samples = resampler.Process(mono, samples[:0])
+		outBuf = appendSamples(outBuf[:0], samples)
+
+		if len(outBuf) > 0 {
+			if _, err := w.Write(outBuf); err != nil {
+				return err
+			}
+		}
+
+		if errors.Is(errRead, io.EOF) {
+			return nil
+		}
+
+		if errRead != nil {
+			return errRead
+		}
+	}
+}
+
+// pick returns the configured channel of a multichannel frame.
+func (d *Decode) pick(frame []int16) int16 {
+	if d.channel >= 0 && d.channel < len(frame) {
+		return frame[d.channel]
+	}
+
+	var sum int32
+
+	for _, v := range frame {
+		sum += int32(v)
+	}
+
+	return int16(sum / int32(len(frame)))
+}
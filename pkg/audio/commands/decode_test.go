@@ -0,0 +1,127 @@
+//go:build test && !integration
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		format audio.Format
+		encode func(v int16) []byte
+	}{
+		{
+			format: audio.Format{Codec: audio.CodecSlin, SampleRate: 16000, Channels: 2},
+			encode: func(v int16) []byte { return []byte{byte(v), byte(uint16(v) >> 8), 0, 0} },
+		},
+		{
+			format: audio.Format{Codec: audio.CodecULaw, SampleRate: 8000, Channels: 1},
+			encode: func(v int16) []byte { return []byte{encodeG711(audio.ULawDecode, v)} },
+		},
+		{
+			format: audio.Format{Codec: audio.CodecALaw, SampleRate: 8000, Channels: 1},
+			encode: func(v int16) []byte { return []byte{encodeG711(audio.ALawDecode, v)} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			var input []byte
+
+			for i := 0; i < tt.format.SampleRate; i++ {
+				v := int16(8000 * math.Sin(2*math.Pi*1000*float64(i)/float64(tt.format.SampleRate)))
+				input = append(input, tt.encode(v)...)
+			}
+
+			result := runNativeCommand(t, NewDecode(tt.format, 8000, ChannelMix), input)
+			require.InDelta(t, 2*8000, len(result), 4)
+
+			frame := make([]int16, audio.FrameSamples(8000))
+			for i := range frame {
+				frame[i] = int16(binary.LittleEndian.Uint16(result[8000+2*i:]))
+			}
+
+			purity, level := audio.ToneMeasure(frame, 1000, 8000)
+			require.Greater(t, purity, 0.95)
+
+			expected := -15.2 // rms of the 8000 amplitude tone
+			if tt.format.Channels == 2 {
+				expected -= 6 // mixed with a silent channel
+			}
+
+			require.InDelta(t, expected, level, 1)
+		})
+	}
+}
+
+func TestDecode_InvalidFormat(t *testing.T) {
+	_, _, err := NewDecode(audio.Format{Codec: "gsm", SampleRate: 8000, Channels: 1}, 8000, ChannelMix).Handle(context.Background())
+	require.Error(t, err)
+}
+
+// encodeG711 finds the code whose decoded value is the closest to v.
+func encodeG711(decode func(byte) int16, v int16) byte {
+	best := 0
+
+	for b := 1; b < 256; b++ {
+		if math.Abs(float64(decode(byte(b)))-float64(v)) < math.Abs(float64(decode(byte(best)))-float64(v)) {
+			best = b
+		}
+	}
+
+	return byte(best)
+}
+
+type recordBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (r *recordBuffer) Close() error {
+	r.closed = true
+
+	return nil
+}
+
+func TestDecode_Channel(t *testing.T) {
+	format := audio.Format{Codec: audio.CodecSlin, SampleRate: 8000, Channels: 2}
+
+	var input []byte
+
+	for i := 0; i < 8000; i++ {
+		left := int16(8000 * math.Sin(2*math.Pi*1000*float64(i)/8000))
+		right := int16(8000 * math.Sin(2*math.Pi*1400*float64(i)/8000))
+		input = append(input, byte(left), byte(uint16(left)>>8), byte(right), byte(uint16(right)>>8))
+	}
+
+	record := &recordBuffer{}
+
+	decode := NewDecode(format, 8000, 1)
+	decode.Record(record)
+
+	result := runNativeCommand(t, decode, input)
+	require.Len(t, result, len(input)/2)
+
+	frame := make([]int16, audio.FrameSamples(8000))
+	for i := range frame {
+		frame[i] = int16(binary.LittleEndian.Uint16(result[2*i:]))
+	}
+
+	purity, _ := audio.ToneMeasure(frame, 1400, 8000)
+	require.Greater(t, purity, 0.95)
+
+	purity, _ = audio.ToneMeasure(frame, 1000, 8000)
+	require.Less(t, purity, 0.01)
+
+	// both legs are recorded
+	require.True(t, record.closed)
+	require.Equal(t, input, record.Bytes())
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+const maxStderr = 4096
+
+// Process runs an external program as a PipeCommand stage,
+// sox commands embed it and call Start from Handle.
+type Process struct {
+	cmd    *exec.Cmd
+	stderr limitedBuffer
+	once   sync.Once
+	err    error
+}
+
+func (p *Process) Start(ctx context.Context, name string, args ...string) (in io.WriteCloser, out io.Reader, err error) {
+	p.cmd = exec.CommandContext(ctx, name, args...)
+	p.cmd.Stderr = &p.stderr
+
+	in, err = p.cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err = p.cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = p.cmd.Start()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return in, out, nil
+}
+
+// Wait reaps the process, it must be called after the output is drained.
+func (p *Process) Wait() error {
+	if p.cmd == nil || p.cmd.Process == nil {
+		return nil
+	}
+
+	p.once.Do(func() {
+		err := p.cmd.Wait()
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && p.stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, bytes.TrimSpace(p.stderr.Bytes()))
+		}
+
+		p.err = err
+	})
+
+	return p.err
+}
+
+// limitedBuffer keeps the beginning of the process stderr.
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if free := maxStderr - b.buf.Len(); free > 0 {
+		if len(p) > free {
+			b.buf.Write(p[:free])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Len()
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Bytes()
+}
@@ -0,0 +1,88 @@
+//go:build test && !integration
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type shellCommand struct {
+	Process
+
+	script string
+}
+
+func (c *shellCommand) Name() string {
+	return "sh " + c.script
+}
+
+func (c *shellCommand) Handle(ctx context.Context) (io.WriteCloser, io.Reader, error) {
+	return c.Start(ctx, "sh", "-c", c.script)
+}
+
+func TestProcess_Exit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	first, second := &shellCommand{script: "cat"}, &shellCommand{script: "cat"}
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{first, second})
+	require.NoError(t, err)
+	require.NoError(t, pipe.Start(context.Background()))
+
+	input := bytes.Repeat([]byte("pcm"), 5000)
+
+	go func() {
+		_ = pipe.Write(input)
+		_ = pipe.Close()
+	}()
+
+	result, err := io.ReadAll(pipe.StdOut)
+	require.NoError(t, err)
+	require.Equal(t, input, result)
+	require.NoError(t, pipe.Wait())
+
+	require.True(t, first.cmd.ProcessState.Exited())
+	require.True(t, second.cmd.ProcessState.Exited())
+}
+
+func TestProcess_Cancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	command := &shellCommand{script: "cat"}
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{command})
+	require.NoError(t, err)
+	require.NoError(t, pipe.Start(ctx))
+	require.NoError(t, pipe.Write([]byte("pcm")))
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	require.NoError(t, pipe.Wait())
+	require.NotNil(t, command.cmd.ProcessState)
+}
+
+func TestProcess_Error(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	command := &shellCommand{script: "echo 'sox FAIL' >&2; exit 2"}
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{command})
+	require.NoError(t, err)
+	require.NoError(t, pipe.Start(context.Background()))
+
+	_, _ = io.Copy(io.Discard, pipe.StdOut)
+
+	require.EqualError(t, pipe.Wait(), "sh echo 'sox FAIL' >&2; exit 2: exit status 2: sox FAIL")
+	require.NotNil(t, command.cmd.ProcessState)
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"io"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+// PcmRawToWavNative wraps raw signed 16-bit pcm into a streaming wav, same output as PcmRawToWav.
+type PcmRawToWavNative struct {
+	audio.Stream
+
+	format audio.WavFormat
+}
+
+func NewPcmRawToWavNative(sampleRate int) *PcmRawToWavNative {
+	return &PcmRawToWavNative{
+		format: audio.WavFormat{
+			SampleRate:    sampleRate,
+			Channels:      1,
+			BitsPerSample: 16,
+		},
+	}
+}
+
+func (p *PcmRawToWavNative) Name() string {
+	return "native_raw_to_wav"
+}
+
+func (p *PcmRawToWavNative) Handle(ctx context.Context) (in io.WriteCloser, out io.Reader, err error) {
+	in, out = p.Start(ctx, p.convert)
+
+	return in, out, nil
+}
+
+func (p *PcmRawToWavNative) convert(r io.Reader, w io.Writer) error {
+	_, err := w.Write(audio.WavHeader(p.format, audio.WavStreamDataSize))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, r)
+
+	return err
+}
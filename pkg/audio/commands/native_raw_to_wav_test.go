@@ -0,0 +1,71 @@
+//go:build test && !integration
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	testdata "github.com/Arten331/bot-checker/data/test"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPcmRawToWavNative(t *testing.T) {
+	testFS := testdata.GetTestFS()
+
+	raw, err := testFS.ReadFile("sox/raw_from_fork.pcm")
+	require.NoError(t, err)
+
+	expected, err := testFS.ReadFile("sox/raw_from_fork_wav.wav")
+	require.NoError(t, err)
+
+	result := runNativeCommand(t, NewPcmRawToWavNative(8000), raw)
+
+	require.Equal(t, expected[:audio.WavHeaderSize], result[:audio.WavHeaderSize])
+	require.Len(t, result, audio.WavHeaderSize+len(raw))
+
+	// the sox golden file was captured before its buffer was flushed
+	require.Equal(t, expected, result[:len(expected)])
+}
+
+// runNativeCommand writes input to the command in random sized chunks and returns all its output.
+func runNativeCommand(t *testing.T, command audio.PipeCommand, input []byte) []byte {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in, out, err := command.Handle(ctx)
+	require.NoError(t, err)
+
+	go func() {
+		chunks := []int{320, 1, 4999, 8000, 7}
+
+		for i := 0; len(input) > 0; i++ {
+			n := chunks[i%len(chunks)]
+			if n > len(input) {
+				n = len(input)
+			}
+
+			_, err := in.Write(input[:n])
+			if err != nil {
+				return
+			}
+
+			input = input[n:]
+		}
+
+		_ = in.Close()
+	}()
+
+	var result bytes.Buffer
+
+	_, err = io.Copy(&result, out)
+	require.NoError(t, err)
+	require.NoError(t, command.Wait())
+
+	return result.Bytes()
+}
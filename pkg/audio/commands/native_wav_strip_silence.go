@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+)
+
+// Same arguments as the sox effect: silence -l 1 0.1 1% -1 2.0 1%
+const (
+	silenceAboveDuration = 0.1
+	silenceBelowDuration = 2.0
+	silenceThreshold     = 1.0 // percent of full scale
+	silenceWindowPerSec  = 50  // sox rms window is 1/50 s
+)
+
+var errUnsupportedWav = errors.New("only 16 bit pcm wav is supported")
+
+// PcmWavStripSilenceNative is a Go port of the sox silence effect used by PcmWavStripSilence.
+// Audio is dropped until 0.1 s stays above 1% rms, and after 2 s of silence the leading
+// trim starts over, so every pause is cut down to 2 s.
+type PcmWavStripSilenceNative struct {
+	audio.Stream
+}
+
+func NewPcmWavSilenceNative() *PcmWavStripSilenceNative {
+	return &PcmWavStripSilenceNative{}
+}
+
+func (p *PcmWavStripSilenceNative) Name() string {
+	return "native_wav_strip_silence"
+}
+
+func (p *PcmWavStripSilenceNative) Handle(ctx context.Context) (in io.WriteCloser, out io.Reader, err error) {
+	in, out = p.Start(ctx, p.strip)
+
+	return in, out, nil
+}
+
+func (p *PcmWavStripSilenceNative) strip(r io.Reader, w io.Writer) error {
+	br := bufio.NewReaderSize(r, audio.BUFFSIZE)
+
+	format, err := audio.ReadWavHeader(br)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if format.BitsPerSample != 16 || format.Channels < 1 {
+		return errUnsupportedWav
+	}
+
+	_, err = w.Write(audio.WavHeader(format, audio.WavStreamDataSize))
+	if err != nil {
+		return err
+	}
+
+	s := newSilenceStripper(format.SampleRate, format.Channels)
+
+	buf := make([]byte, audio.BUFFSIZE)
+	frame := make([]int16, 0, format.Channels)
+	outBuf := make([]byte, 0, audio.BUFFSIZE)
+
+	var pending []byte
+
+	for {
+		n, errRead := br.Read(buf)
+
+		data := append(pending, buf[:n]...)
+		outBuf = outBuf[:0]
+
+		for len(data) >= 2*format.Channels {
+			frame = frame[:0]
+
+			for c := 0; c < format.Channels; c++ {
+				frame = append(frame, int16(binary.LittleEndian.Uint16(data[2*c:])))
+			}
+
+			data = data[2*format.Channels:]
+
+			outBuf = s.push(frame, outBuf)
+		}
+
+		pending = append(pending[:0], data...)
+
+		if len(outBuf) > 0 {
+			if _, err = w.Write(outBuf); err != nil {
+				return err
+			}
+		}
+
+		if errors.Is(errRead, io.EOF) {
+			return nil
+		}
+
+		if errRead != nil {
+			return errRead
+		}
+	}
+}
+
+type silenceStripper struct {
+	window    []float64
+	windowPos int
+	rmsSum    float64
+
+	trimming    bool
+	aboveNeeded int
+	belowNeeded int
+	holdoff     []int16
+	belowCount  int
+	threshold   float64
+}
+
+func newSilenceStripper(sampleRate, channels int) *silenceStripper {
+	return &silenceStripper{
+		window:      make([]float64, sampleRate/silenceWindowPerSec*channels),
+		trimming:    true,
+		aboveNeeded: int(silenceAboveDuration * float64(sampleRate)),
+		belowNeeded: int(silenceBelowDuration * float64(sampleRate)),
+		threshold:   silenceThreshold,
+	}
+}
+
+// push feeds one multichannel frame and appends the samples to be emitted to out.
+func (s *silenceStripper) push(frame []int16, out []byte) []byte {
+	if s.trimming {
+		above := false
+
+		for _, v := range frame {
+			above = above || s.above(v)
+		}
+
+		for _, v := range frame {
+			s.updateRMS(v)
+		}
+
+		if !above {
+			s.holdoff = s.holdoff[:0]
+
+			return out
+		}
+
+		s.holdoff = append(s.holdoff, frame...)
+		if len(s.holdoff) < s.aboveNeeded*len(frame) {
+			return out
+		}
+
+		s.trimming = false
+		s.belowCount = 0
+
+		out = appendSamples(out, s.holdoff)
+		s.holdoff = s.holdoff[:0]
+
+		return out
+	}
+
+	above := true
+
+	for _, v := range frame {
+		above = above && s.above(v)
+	}
+
+	for _, v := range frame {
+		s.updateRMS(v)
+	}
+
+	if !above {
+		// with silence left in place sox keeps counting silent frames across short bursts
+		s.belowCount++
+		if s.belowCount >= s.belowNeeded {
+			s.restart()
+		}
+	}
+
+	return appendSamples(out, frame)
+}
+
+func (s *silenceStripper) restart() {
+	s.trimming = true
+	s.belowCount = 0
+	s.holdoff = s.holdoff[:0]
+	s.rmsSum = 0
+	s.windowPos = 0
+
+	for i := range s.window {
+		s.window[i] = 0
+	}
+}
+
+func (s *silenceStripper) above(v int16) bool {
+	sample := float64(int32(v) << 16)
+	sum := s.rmsSum - s.window[s.windowPos] + sample*sample
+
+	// sox compares only the original 16 bits of the 32 bit rms
+	rms := int32(math.Sqrt(sum/float64(len(s.window)))) &^ 0xFFFF
+
+	return float64(rms)/math.MaxInt32*100 > s.threshold
+}
+
+func (s *silenceStripper) updateRMS(v int16) {
+	sample := float64(int32(v) << 16)
+
+	s.rmsSum -= s.window[s.windowPos]
+	s.window[s.windowPos] = sample * sample
+	s.rmsSum += s.window[s.windowPos]
+
+	s.windowPos = (s.windowPos + 1) % len(s.window)
+}
+
+func appendSamples(out []byte, samples []int16) []byte {
+	for _, v := range samples {
+		out = append(out, byte(v), byte(uint16(v)>>8))
+	}
+
+	return out
+}
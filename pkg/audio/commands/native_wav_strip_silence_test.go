@@ -0,0 +1,88 @@
+//go:build test && !integration
+
+package commands
+
+import (
+	"math"
+	"testing"
+
+	testdata "github.com/Arten331/bot-checker/data/test"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPcmWavStripSilenceNative(t *testing.T) {
+	testFS := testdata.GetTestFS()
+
+	wav, err := testFS.ReadFile("sox/raw_from_fork_wav.wav")
+	require.NoError(t, err)
+
+	expected, err := testFS.ReadFile("sox/wav_silenced.wav")
+	require.NoError(t, err)
+
+	result := runNativeCommand(t, NewPcmWavSilenceNative(), wav)
+
+	// the sox golden file was captured before its buffer was flushed, its tail is missing
+	require.Equal(t, audio.WavHeader(audio.WavFormat{SampleRate: 8000, Channels: 1, BitsPerSample: 16}, audio.WavStreamDataSize),
+		result[:audio.WavHeaderSize])
+	require.GreaterOrEqual(t, len(result), len(expected))
+	require.Equal(t, expected, result[:len(expected)])
+}
+
+func TestPcmWavStripSilenceNative_Pauses(t *testing.T) {
+	const rate = 8000
+
+	format := audio.WavFormat{SampleRate: rate, Channels: 1, BitsPerSample: 16}
+
+	// seconds of tone and silence
+	parts := []struct {
+		seconds float64
+		tone    bool
+	}{{0.5, false}, {1, true}, {3, false}, {0.5, true}, {3, false}}
+
+	wav := audio.WavHeader(format, audio.WavStreamDataSize)
+
+	for _, part := range parts {
+		for i := 0; i < int(part.seconds*rate); i++ {
+			var v int16
+			if part.tone {
+				v = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/rate))
+			}
+
+			wav = append(wav, byte(v), byte(uint16(v)>>8))
+		}
+	}
+
+	result := runNativeCommand(t, NewPcmWavSilenceNative(), wav)
+
+	require.Equal(t, wav[:audio.WavHeaderSize], result[:audio.WavHeaderSize])
+
+	// the leading silence is dropped, every pause and the tail are cut down to 2 s;
+	// the edges move by the rms window of 1/50 s
+	want := audio.WavHeaderSize + 2*rate*(1+2+0.5+2)
+	require.InDelta(t, want, len(result), 4*2*rate/silenceWindowPerSec)
+}
+
+func TestPcmWavStripSilenceNative_Noise(t *testing.T) {
+	wav, err := testdata.GetTestFS().ReadFile("sox/noise.wav")
+	require.NoError(t, err)
+
+	result := runNativeCommand(t, NewPcmWavSilenceNative(), wav)
+
+	require.Len(t, result, audio.WavHeaderSize)
+}
+
+func TestNativePipeline(t *testing.T) {
+	testFS := testdata.GetTestFS()
+
+	raw, err := testFS.ReadFile("sox/raw_from_fork.pcm")
+	require.NoError(t, err)
+
+	expected, err := testFS.ReadFile("sox/pipe_silenced.wav")
+	require.NoError(t, err)
+
+	wav := runNativeCommand(t, NewPcmRawToWavNative(8000), raw)
+	result := runNativeCommand(t, NewPcmWavSilenceNative(), wav)
+
+	require.Equal(t, expected, result[:len(expected)])
+}
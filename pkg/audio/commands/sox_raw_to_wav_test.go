@@ -11,7 +11,6 @@ import (
 	"time"
 
 	testdata "github.com/Arten331/bot-checker/data/test"
-	"github.com/Arten331/bot-checker/pkg/audio"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/ratelimit"
 )
@@ -20,18 +19,10 @@ func TestPcmRawToWav(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	soxHandler := NewPcmRawToWav()
+	soxHandler := NewPcmRawToWav(8000)
 
-	errChan := make(audio.ErrChan, 1)
-
-	in, out := soxHandler.Handle(ctx, errChan)
-
-	go func() {
-		err := <-errChan
-
-		t.Errorf("Error: %v", err)
-		return
-	}()
+	in, out, err := soxHandler.Handle(ctx)
+	require.NoError(t, err)
 
 	testFS := testdata.GetTestFS()
 	testFile, err := testFS.Open("sox/raw_from_fork.pcm")
@@ -49,6 +40,7 @@ func TestPcmRawToWav(t *testing.T) {
 			buf := make([]byte, 320)
 			_, err := testFile.Read(buf)
 			if err == io.EOF {
+				_ = in.Close()
 				pcmReaden <- nil
 
 				return
@@ -74,6 +66,8 @@ func TestPcmRawToWav(t *testing.T) {
 
 	time.Sleep(1000 * time.Millisecond)
 
+	require.NoError(t, soxHandler.Wait())
+
 	expectedFile, err := testFS.Open("sox/raw_from_fork_wav.wav")
 	require.NoError(t, err)
 
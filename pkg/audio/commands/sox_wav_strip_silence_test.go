@@ -11,7 +11,6 @@ import (
 	"time"
 
 	testdata "github.com/Arten331/bot-checker/data/test"
-	"github.com/Arten331/bot-checker/pkg/audio"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/ratelimit"
 )
@@ -22,16 +21,8 @@ func TestPcmWavStripSilence(t *testing.T) {
 
 	soxHandler := NewPcmWavSilence()
 
-	errChan := make(audio.ErrChan, 1)
-
-	in, out := soxHandler.Handle(ctx, errChan)
-
-	go func() {
-		err := <-errChan
-
-		t.Errorf("Error: %v", err)
-		return
-	}()
+	in, out, err := soxHandler.Handle(ctx)
+	require.NoError(t, err)
 
 	testFS := testdata.GetTestFS()
 	testFile, err := testFS.Open("sox/raw_from_fork_wav.wav")
@@ -49,6 +40,7 @@ func TestPcmWavStripSilence(t *testing.T) {
 			buf := make([]byte, rand.Intn(5000))
 			_, err := testFile.Read(buf)
 			if err == io.EOF {
+				_ = in.Close()
 				pcmReaden <- nil
 
 				return
@@ -74,6 +66,8 @@ func TestPcmWavStripSilence(t *testing.T) {
 
 	time.Sleep(1000 * time.Millisecond)
 
+	require.NoError(t, soxHandler.Wait())
+
 	expectedFile, err := testFS.Open("sox/wav_silenced.wav")
 	require.NoError(t, err)
 
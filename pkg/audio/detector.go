@@ -0,0 +1,39 @@
+package audio
+
+import (
+	"time"
+)
+
+// FrameDuration is the analysis frame length, as in WebRTC VAD.
+const FrameDuration = 20 * time.Millisecond
+
+type EventType string
+
+const (
+	EventSpeechStart EventType = "speech_start"
+	EventSpeechEnd   EventType = "speech_end"
+)
+
+// Event is reported by a Detector, At is the offset from the beginning of the stream.
+type Event struct {
+	Type     EventType
+	At       time.Duration
+	Duration time.Duration
+	Label    string
+}
+
+// Detector inspects consecutive frames of signed 16-bit mono pcm.
+type Detector interface {
+	Detect(frame []int16, at time.Duration) (Event, bool)
+}
+
+// Gate is a Detector that decides whether audio should be passed further down the pipe.
+type Gate interface {
+	Detector
+	Open() bool
+}
+
+// FrameSamples returns the number of samples in one analysis frame.
+func FrameSamples(sampleRate int) int {
+	return sampleRate * int(FrameDuration/time.Millisecond) / 1000
+}
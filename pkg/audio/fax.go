@@ -0,0 +1,61 @@
+package audio
+
+import "time"
+
+const EventFax EventType = "fax"
+
+// Fax tones, the event label.
+const (
+	FaxCNG = "CNG" // calling tone of a sending machine
+	FaxCED = "CED" // answer tone of a receiving fax or modem, also ANSam
+)
+
+const (
+	cngFrequency = 1100
+	cedFrequency = 2100
+
+	cngMinBurst    = 400 * time.Millisecond // bursts are 0.5 s long
+	cngMaxBurst    = 700 * time.Millisecond
+	cedMinDuration = time.Second // the tone lasts 2.6 - 4 s
+)
+
+type FaxOptions struct {
+	ThresholdDB float64
+}
+
+// FaxDetector reports fax and modem tones: a single CNG burst or the beginning of CED.
+type FaxDetector struct {
+	cng      toneTracker
+	ced      toneTracker
+	reported bool
+}
+
+func NewFaxDetector(sampleRate int, o FaxOptions) *FaxDetector {
+	return &FaxDetector{
+		cng: newToneTracker(cngFrequency, sampleRate, o.ThresholdDB),
+		ced: newToneTracker(cedFrequency, sampleRate, o.ThresholdDB),
+	}
+}
+
+func (d *FaxDetector) Detect(frame []int16, at time.Duration) (Event, bool) {
+	burst, burstStart := d.cng.length, d.cng.start
+	cng := !d.cng.track(frame, at) && d.cng.length == 0 && burst >= cngMinBurst && burst <= cngMaxBurst
+	ced := d.ced.track(frame, at) && d.ced.length >= cedMinDuration
+
+	if d.reported {
+		return Event{}, false
+	}
+
+	switch {
+	case ced:
+		d.reported = true
+
+		return Event{Type: EventFax, At: d.ced.start, Duration: d.ced.length, Label: FaxCED}, true
+	case cng:
+		d.reported = true
+
+		return Event{Type: EventFax, At: burstStart, Duration: burst, Label: FaxCNG}, true
+	}
+
+	return Event{}, false
+}
@@ -0,0 +1,51 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+var testFax = audio.FaxOptions{ThresholdDB: -40}
+
+func TestFaxDetector_CNG(t *testing.T) {
+	samples := synth(8 * time.Second)
+	addTone(samples, 1100, 4000, time.Second, 1500*time.Millisecond)
+	addTone(samples, 1100, 4000, 4500*time.Millisecond, 5*time.Second)
+
+	events := detectAll(audio.NewFaxDetector(testSampleRate, testFax), samples)
+	require.Len(t, events, 1)
+
+	require.Equal(t, audio.EventFax, events[0].Type)
+	require.Equal(t, audio.FaxCNG, events[0].Label)
+	require.InDelta(t, time.Second, events[0].At, float64(audio.FrameDuration))
+	require.InDelta(t, 500*time.Millisecond, events[0].Duration, float64(audio.FrameDuration))
+}
+
+func TestFaxDetector_CED(t *testing.T) {
+	samples := synth(5 * time.Second)
+	addTone(samples, 2100, 4000, time.Second, 4*time.Second)
+
+	events := detectAll(audio.NewFaxDetector(testSampleRate, testFax), samples)
+	require.Len(t, events, 1)
+
+	require.Equal(t, audio.FaxCED, events[0].Label)
+	require.InDelta(t, time.Second, events[0].At, float64(audio.FrameDuration))
+	require.InDelta(t, time.Second, events[0].Duration, float64(audio.FrameDuration))
+}
+
+func TestFaxDetector_NotFax(t *testing.T) {
+	samples := synth(5 * time.Second)
+	addTone(samples, 1100, 4000, time.Second, 3*time.Second) // too long for CNG
+	addTone(samples, 2100, 4000, 3500*time.Millisecond, 4*time.Second)
+
+	require.Empty(t, detectAll(audio.NewFaxDetector(testSampleRate, testFax), samples))
+
+	for _, name := range []string{"ABONENT_NE_MOJZHET.wav", "BLOCKED.wav", "BUSY_WAITING.wav", "DISCONNECTED1.wav", "SUBSCRIBER_NOT_AVAIL.wav"} {
+		require.Empty(t, detectAll(audio.NewFaxDetector(testSampleRate, testFax), readRecord(t, name)), name)
+	}
+}
@@ -0,0 +1,83 @@
+package audio
+
+import "fmt"
+
+// Codecs of raw audio input.
+const (
+	CodecSlin = "slin" // signed 16-bit little endian pcm
+	CodecULaw = "ulaw"
+	CodecALaw = "alaw"
+)
+
+// Format describes raw interleaved audio.
+type Format struct {
+	Codec      string
+	SampleRate int
+	Channels   int
+}
+
+// Validate reports an error for formats that can not be decoded.
+func (f Format) Validate() error {
+	switch f.Codec {
+	case CodecSlin, CodecULaw, CodecALaw:
+	default:
+		return fmt.Errorf("unsupported codec %q", f.Codec)
+	}
+
+	if f.SampleRate < 8000 || f.SampleRate > 48000 {
+		return fmt.Errorf("unsupported sample rate %d", f.SampleRate)
+	}
+
+	if f.Channels != 1 && f.Channels != 2 {
+		return fmt.Errorf("unsupported channels %d", f.Channels)
+	}
+
+	return nil
+}
+
+// SampleSize returns the size of one sample of one channel in bytes.
+func (f Format) SampleSize() int {
+	if f.Codec == CodecSlin {
+		return 2
+	}
+
+	return 1
+}
+
+// Decode converts one sample of the format to signed 16-bit pcm.
+func (f Format) Decode(b []byte) int16 {
+	switch f.Codec {
+	case CodecULaw:
+		return ULawDecode(b[0])
+	case CodecALaw:
+		return ALawDecode(b[0])
+	default:
+		return int16(uint16(b[0]) | uint16(b[1])<<8)
+	}
+}
+
+// Silence returns n bytes of silence in the format.
+func (f Format) Silence(n int) []byte {
+	var value byte
+
+	switch f.Codec {
+	case CodecULaw:
+		value = 0xff
+	case CodecALaw:
+		value = 0xd5
+	}
+
+	b := make([]byte, n)
+
+	if value != 0 {
+		for i := range b {
+			b[i] = value
+		}
+	}
+
+	return b
+}
+
+func (f Format) String() string {
+	return fmt.Sprintf("%s/%d/%d", f.Codec, f.SampleRate, f.Channels)
+}
@@ -0,0 +1,96 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestG711Decode(t *testing.T) {
+	require.Equal(t, int16(0), audio.ULawDecode(0xFF))
+	require.Equal(t, int16(-32124), audio.ULawDecode(0x00))
+	require.Equal(t, int16(32124), audio.ULawDecode(0x80))
+
+	require.Equal(t, int16(8), audio.ALawDecode(0xD5))
+	require.Equal(t, int16(-8), audio.ALawDecode(0x55))
+	require.Equal(t, int16(32256), audio.ALawDecode(0xAA))
+
+	// positive halves of both laws grow monotonically
+	for b := 0x80; b < 0xFF; b++ {
+		require.Greater(t, audio.ULawDecode(byte(b)), audio.ULawDecode(byte(b+1)))
+	}
+
+	for v := 1; v < 0x80; v++ {
+		require.Greater(t, audio.ALawDecode(byte(v^0xD5)), audio.ALawDecode(byte((v-1)^0xD5)))
+	}
+}
+
+func TestFormat_Validate(t *testing.T) {
+	require.NoError(t, audio.Format{Codec: audio.CodecULaw, SampleRate: 8000, Channels: 2}.Validate())
+	require.Error(t, audio.Format{Codec: "opus", SampleRate: 8000, Channels: 1}.Validate())
+	require.Error(t, audio.Format{Codec: audio.CodecSlin, SampleRate: 0, Channels: 1}.Validate())
+	require.Error(t, audio.Format{Codec: audio.CodecSlin, SampleRate: 8000, Channels: 3}.Validate())
+}
+
+func TestFormat_Silence(t *testing.T) {
+	for _, codec := range []string{audio.CodecSlin, audio.CodecULaw, audio.CodecALaw} {
+		f := audio.Format{Codec: codec, SampleRate: 8000, Channels: 1}
+		silence := f.Silence(4)
+
+		require.Len(t, silence, 4)
+
+		for i := 0; i < len(silence); i += f.SampleSize() {
+			require.InDelta(t, 0, f.Decode(silence[i:]), 8, codec)
+		}
+	}
+}
+
+func TestResampler(t *testing.T) {
+	for _, rate := range []int{16000, 48000, 8000} {
+		samples := make([]int16, 2*rate)
+		addToneAt(samples, 1000, 8000, rate)
+
+		r := audio.NewResampler(rate, testSampleRate)
+
+		var out []int16
+
+		// in uneven chunks, as read from a pipe
+		for i := 0; i < len(samples); i += 333 {
+			end := i + 333
+			if end > len(samples) {
+				end = len(samples)
+			}
+
+			out = r.Process(samples[i:end], out)
+		}
+
+		require.InDelta(t, 2*testSampleRate, len(out), 2, rate)
+
+		frame := out[testSampleRate : testSampleRate+audio.FrameSamples(testSampleRate)]
+		purity, level := audio.ToneMeasure(frame, 1000, testSampleRate)
+		require.Greater(t, purity, 0.95, rate)
+		require.InDelta(t, -15, level, 1.5, rate)
+	}
+}
+
+func TestResampler_Upsample(t *testing.T) {
+	samples := make([]int16, testSampleRate)
+	addToneAt(samples, 1000, 8000, testSampleRate)
+
+	out := audio.NewResampler(testSampleRate, 16000).Process(samples, nil)
+	require.InDelta(t, 16000, len(out), 2)
+
+	purity, _ := audio.ToneMeasure(out[8000:8000+audio.FrameSamples(16000)], 1000, 16000)
+	require.Greater(t, purity, 0.95)
+}
+
+// addToneAt mixes a tone into the whole of samples at the sample rate.
+func addToneAt(samples []int16, freq, amplitude float64, sampleRate int) {
+	for i := range samples {
+		samples[i] += int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+}
@@ -0,0 +1,41 @@
+package audio
+
+// ULawDecode expands a G.711 μ-law byte to a signed 16-bit sample.
+func ULawDecode(b byte) int16 {
+	b = ^b
+
+	exponent := (b >> 4) & 0x07
+	mantissa := int16(b & 0x0F)
+	sample := ((mantissa << 3) + 0x84) << exponent
+
+	sample -= 0x84
+
+	if b&0x80 != 0 {
+		return -sample
+	}
+
+	return sample
+}
+
+// ALawDecode expands a G.711 A-law byte to a signed 16-bit sample.
+func ALawDecode(b byte) int16 {
+	b ^= 0x55
+
+	exponent := (b >> 4) & 0x07
+	mantissa := int16(b & 0x0F)
+
+	var sample int16
+
+	switch exponent {
+	case 0:
+		sample = (mantissa << 4) + 8
+	default:
+		sample = ((mantissa << 4) + 0x108) << (exponent - 1)
+	}
+
+	if b&0x80 == 0 {
+		return -sample
+	}
+
+	return sample
+}
@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	tonePurity = 0.6 // share of the frame energy that must belong to the tone
+	toneMisses = 1   // single frames without the tone do not break it
+)
+
+// Goertzel returns the squared magnitude of freq in samples, |X(f)|^2.
+func Goertzel(samples []int16, freq float64, sampleRate int) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*freq/float64(sampleRate))
+
+	var s1, s2 float64
+
+	for _, x := range samples {
+		s0 := float64(x) + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
+
+// ToneMeasure returns the share of frame energy at freq, 1 for a pure tone,
+// and the tone rms level in dBFS.
+func ToneMeasure(frame []int16, freq float64, sampleRate int) (purity, levelDB float64) {
+	if len(frame) == 0 {
+		return 0, math.Inf(-1)
+	}
+
+	var energy float64
+
+	for _, x := range frame {
+		energy += float64(x) * float64(x)
+	}
+
+	if energy == 0 {
+		return 0, math.Inf(-1)
+	}
+
+	n := float64(len(frame))
+	power := Goertzel(frame, freq, sampleRate)
+
+	purity = 2 * power / (n * energy)
+	levelDB = 20 * math.Log10(math.Sqrt(2*power)/n/math.MaxInt16)
+
+	return purity, levelDB
+}
+
+// toneTracker follows a continuous tone at one frequency over consecutive frames.
+type toneTracker struct {
+	freq        float64
+	sampleRate  int
+	thresholdDB float64
+
+	start    time.Duration
+	length   time.Duration
+	misses   int
+	reported bool
+}
+
+func newToneTracker(freq float64, sampleRate int, thresholdDB float64) toneTracker {
+	return toneTracker{
+		freq:        freq,
+		sampleRate:  sampleRate,
+		thresholdDB: thresholdDB,
+	}
+}
+
+// track feeds one frame and reports whether the tone is present in it.
+func (t *toneTracker) track(frame []int16, at time.Duration) bool {
+	purity, level := ToneMeasure(frame, t.freq, t.sampleRate)
+
+	if purity >= tonePurity && level >= t.thresholdDB {
+		if t.length == 0 {
+			t.start = at
+		}
+
+		t.length = at + FrameDuration - t.start
+		t.misses = 0
+
+		return true
+	}
+
+	if t.length > 0 {
+		t.misses++
+		if t.misses > toneMisses {
+			t.reset()
+		}
+	}
+
+	return false
+}
+
+func (t *toneTracker) reset() {
+	t.length = 0
+	t.misses = 0
+	t.reported = false
+}
@@ -2,15 +2,24 @@ package audio
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
-	"os"
+	"sync"
 )
 
 const BUFFSIZE = 8000
 
+var ErrPipeEmpty = errors.New("audio pipe requires at least one command")
+
+// PipeCommand is a single stage of the Pipe.
+// Handle starts the stage: in receives the stage input and is closed by the Pipe at the end of it,
+// out yields the stage output until EOF. Wait blocks until the stage released its goroutines
+// and child processes, it is called once out is drained or the Pipe context is done.
 type PipeCommand interface {
 	Name() string
-	Handle(ctx context.Context, errChan ErrChan) (in io.Writer, out io.Reader)
+	Handle(ctx context.Context) (in io.WriteCloser, out io.Reader, err error)
+	Wait() error
 }
 
 type PipeErrer interface {
@@ -18,73 +27,85 @@ type PipeErrer interface {
 	Error() string
 }
 
-type ErrChan chan PipeErrer
-
+// Pipe chains commands: StdIn feeds the first one, StdOut yields the output of the last one.
 type Pipe struct {
-	StdErr   ErrChan
-	StdIn    io.Writer
+	StdIn    io.WriteCloser
 	StdOut   io.Reader
 	Commands []PipeCommand
+
+	input  *io.PipeReader
+	output *io.PipeWriter
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stages  sync.WaitGroup
+	watcher sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
 }
 
 func NewPipe(commands []PipeCommand) (*Pipe, error) {
-	rp, wp, err := os.Pipe()
-	if err != nil {
-		return nil, err
+	if len(commands) == 0 {
+		return nil, ErrPipeEmpty
 	}
 
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
 	p := Pipe{
-		StdErr:   make(ErrChan, 1),
-		StdIn:    wp,
-		StdOut:   rp,
+		StdIn:    inW,
+		StdOut:   outR,
 		Commands: commands,
+		input:    inR,
+		output:   outW,
 	}
 
 	return &p, nil
 }
 
-func (p *Pipe) Run(ctx context.Context) error {
-	for _, command := range p.Commands {
-		var curReader io.Reader
+// Start launches all commands. The pipe stops when ctx is done, when any command fails,
+// or after Close once the input has been drained through every command.
+func (p *Pipe) Start(ctx context.Context) error {
+	p.ctx, p.cancel = context.WithCancel(ctx)
 
-		curReader = p.StdOut
-		stdIn, stdOut := command.Handle(ctx, p.StdErr)
+	var (
+		src    io.Reader = p.input
+		srcCmd PipeCommand
+	)
 
-		rp, wp, err := os.Pipe()
+	for _, command := range p.Commands {
+		in, out, err := command.Handle(p.ctx)
 		if err != nil {
-			return err
+			p.fail(command, err)
+
+			break
 		}
 
-		go func() {
-			buf := make([]byte, BUFFSIZE)
+		p.forward(src, srcCmd, in, command)
 
-			for {
-				_, err := io.ReadFull(stdOut, buf)
-				if err != nil {
-					p.StdErr <- &CommandErr{command: command, err: err.Error()}
-				}
+		src, srcCmd = out, command
+	}
 
-				_, _ = wp.Write(buf)
-			}
-		}()
+	if p.Err() == nil {
+		p.forward(src, srcCmd, p.output, nil)
+	} else {
+		_ = p.input.CloseWithError(p.Err())
+		_ = p.output.CloseWithError(p.Err())
+	}
 
-		go func(curReader io.Reader) {
-			for {
-				buf := make([]byte, BUFFSIZE)
+	p.watcher.Add(1)
 
-				_, err := io.ReadFull(curReader, buf)
-				if err != nil {
-					p.StdErr <- &CommandErr{command: command, err: err.Error()}
-				}
+	go func() {
+		defer p.watcher.Done()
 
-				_, _ = stdIn.Write(buf)
-			}
-		}(curReader)
+		<-p.ctx.Done()
 
-		p.StdOut = rp
-	}
+		_ = p.input.CloseWithError(p.ctx.Err())
+		_ = p.output.CloseWithError(p.ctx.Err())
+	}()
 
-	return nil
+	return p.Err()
 }
 
 func (p *Pipe) Write(dat []byte) error {
@@ -96,12 +117,97 @@ func (p *Pipe) Write(dat []byte) error {
 	return nil
 }
 
+// Close ends the input, the commands flush their output and StdOut reaches EOF.
+func (p *Pipe) Close() error {
+	return p.StdIn.Close()
+}
+
+// Wait blocks until every goroutine and command of the pipe has exited.
+// It returns the first command error, stopping the pipe by its context is not an error.
+func (p *Pipe) Wait() error {
+	p.stages.Wait()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	p.watcher.Wait()
+
+	return p.Err()
+}
+
+func (p *Pipe) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// forward copies src to dst exactly as read, closes dst at the end of src and waits for
+// the command that produced src.
+func (p *Pipe) forward(src io.Reader, srcCmd PipeCommand, dst io.WriteCloser, dstCmd PipeCommand) {
+	p.stages.Add(1)
+
+	go func() {
+		defer p.stages.Done()
+
+		buf := make([]byte, BUFFSIZE)
+
+		for {
+			n, err := src.Read(buf)
+			if n > 0 {
+				if _, errWrite := dst.Write(buf[:n]); errWrite != nil {
+					p.fail(dstCmd, errWrite)
+
+					break
+				}
+			}
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				p.fail(srcCmd, err)
+
+				break
+			}
+		}
+
+		_ = dst.Close()
+
+		if srcCmd != nil {
+			if err := srcCmd.Wait(); err != nil {
+				p.fail(srcCmd, err)
+			}
+		}
+	}()
+}
+
+// fail keeps the first error and stops the pipe, errors caused by the stop itself are dropped.
+func (p *Pipe) fail(command PipeCommand, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil || p.ctx.Err() != nil {
+		return
+	}
+
+	if command == nil {
+		p.err = fmt.Errorf("audio pipe: %w", err)
+	} else {
+		p.err = NewErr(command, err)
+	}
+
+	p.cancel()
+}
+
 type CommandErr struct {
 	command PipeCommand
-	err     string
+	err     error
 }
 
-func NewErr(command PipeCommand, err string) *CommandErr {
+func NewErr(command PipeCommand, err error) *CommandErr {
 	return &CommandErr{command: command, err: err}
 }
 
@@ -110,5 +216,9 @@ func (p *CommandErr) Command() PipeCommand {
 }
 
 func (p *CommandErr) Error() string {
+	return p.command.Name() + ": " + p.err.Error()
+}
+
+func (p *CommandErr) Unwrap() error {
 	return p.err
 }
@@ -3,9 +3,12 @@
 package audio_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"io/fs"
+	"math/rand"
 	"os"
 	"testing"
 	"time"
@@ -14,20 +17,19 @@ import (
 	"github.com/Arten331/bot-checker/pkg/audio"
 	commands2 "github.com/Arten331/bot-checker/pkg/audio/commands"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 )
 
 func TestPipe(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	fileReaded := make(chan interface{}, 1)
-
 	testFs := testdata.GetTestFS()
 	fileTest, err := testFs.Open("sox/raw_from_fork.pcm")
 	require.NoError(t, err)
 
 	pipe, err := audio.NewPipe([]audio.PipeCommand{
-		commands2.NewPcmRawToWav(),
+		commands2.NewPcmRawToWav(8000),
 		commands2.NewPcmWavSilence(),
 	})
 	require.NoError(t, err)
@@ -39,71 +41,190 @@ func TestPipe(t *testing.T) {
 	resultAudio, err := os.Create("/tmp/test/pipe" + time.Now().Format(time.RFC3339Nano) + ".wav")
 	require.NoError(t, err)
 
+	err = pipe.Start(ctx)
+	require.NoError(t, err)
+
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				buf := make([]byte, audio.BUFFSIZE)
-				_, err := fileTest.Read(buf)
-				if err == io.EOF {
-					fileReaded <- nil
+		_, _ = io.Copy(pipe.StdIn, fileTest)
+		_ = pipe.Close()
+	}()
+
+	_, err = io.Copy(resultAudio, pipe.StdOut)
+	require.NoError(t, err)
+	require.NoError(t, pipe.Wait())
+
+	result, err := os.ReadFile(resultAudio.Name())
+	require.NoError(t, err)
+
+	expected, err := testFs.ReadFile("sox/pipe_silenced.wav")
+	require.NoError(t, err)
+
+	//compare results
+	require.GreaterOrEqual(t, len(result), len(expected), "not expected file: %s", resultAudio.Name())
+	require.Equalf(t, expected, result[:len(expected)], "not expected file: %s", resultAudio.Name())
+}
+
+type passCommand struct {
+	audio.Stream
+
+	name  string
+	limit int
+}
+
+func (c *passCommand) Name() string {
+	return c.name
+}
 
-					return
-				}
+func (c *passCommand) Handle(ctx context.Context) (io.WriteCloser, io.Reader, error) {
+	in, out := c.Start(ctx, func(r io.Reader, w io.Writer) error {
+		if c.limit == 0 {
+			_, err := io.Copy(w, r)
 
-				err = pipe.Write(buf)
+			return err
+		}
+
+		_, err := io.CopyN(w, r, int64(c.limit))
+		if err != nil {
+			return err
+		}
+
+		return errors.New("limit exceeded")
+	})
+
+	return in, out, nil
+}
+
+type brokenCommand struct{}
+
+func (c brokenCommand) Name() string {
+	return "broken"
+}
+
+func (c brokenCommand) Handle(context.Context) (io.WriteCloser, io.Reader, error) {
+	return nil, nil, errors.New("unable start")
+}
+
+func (c brokenCommand) Wait() error {
+	return nil
+}
+
+func TestPipe_ShortReads(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{
+		&passCommand{name: "first"},
+		&passCommand{name: "second"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, pipe.Start(context.Background()))
 
-				require.NoError(t, err)
+	input := make([]byte, 3*audio.BUFFSIZE+123)
+	rand.Read(input)
+
+	// asserted here, require may not fail the test from another goroutine
+	writeErr := make(chan error, 1)
+
+	go func() {
+		for rest, i := input, 0; len(rest) > 0; i++ {
+			n := []int{1, 7, 320, audio.BUFFSIZE + 1}[i%4]
+			if n > len(rest) {
+				n = len(rest)
+			}
+
+			if err := pipe.Write(rest[:n]); err != nil {
+				_ = pipe.Close()
+				writeErr <- err
+
+				return
 			}
+
+			rest = rest[n:]
 		}
+
+		writeErr <- pipe.Close()
 	}()
 
-	go func() {
-		err := <-pipe.StdErr
+	result, err := io.ReadAll(pipe.StdOut)
+	require.NoError(t, err)
+	require.NoError(t, <-writeErr)
+	require.True(t, bytes.Equal(input, result))
+	require.NoError(t, pipe.Wait())
+}
 
-		t.Errorf("Error: %v", err)
-		t.Failed()
+func TestPipe_CommandError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
 
-		return
+	pipe, err := audio.NewPipe([]audio.PipeCommand{
+		&passCommand{name: "first"},
+		&passCommand{name: "limited", limit: 100},
+		&passCommand{name: "last"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, pipe.Start(context.Background()))
+
+	go func() {
+		for pipe.Write(make([]byte, 10)) == nil {
+		}
 	}()
 
-	err = pipe.Run(ctx)
+	_, _ = io.Copy(io.Discard, pipe.StdOut)
+
+	err = pipe.Wait()
+	require.Error(t, err)
+	require.EqualError(t, err, "limited: limit exceeded")
+
+	var cmdErr audio.PipeErrer
+
+	require.True(t, errors.As(err, &cmdErr))
+	require.Equal(t, "limited", cmdErr.Command().Name())
+}
+
+func TestPipe_Cancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{
+		&passCommand{name: "first"},
+		&passCommand{name: "second"},
+	})
 	require.NoError(t, err)
+	require.NoError(t, pipe.Start(ctx))
+
+	writeErr := make(chan error, 1)
 
+	// nobody reads StdOut, the writer blocks until the pipe is stopped
 	go func() {
 		for {
-			buf := make([]byte, audio.BUFFSIZE)
+			if err := pipe.Write(make([]byte, audio.BUFFSIZE)); err != nil {
+				writeErr <- err
 
-			_, err = pipe.StdOut.Read(buf)
-			if err == io.EOF {
-				break
+				return
 			}
-			require.NoError(t, err)
-
-			_, err = resultAudio.Write(buf)
-			require.NoError(t, err)
 		}
 	}()
 
-	select {
-	case <-fileReaded:
-		<-time.After(2 * time.Second)
-		t.Log("file reader, 2 seconds ago")
-	case <-ctx.Done():
-		t.Log("ctx done")
-	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
 
-	statResAudio, _ := resultAudio.Stat()
-	sizeResAudio := statResAudio.Size()
+	require.NoError(t, pipe.Wait())
+	require.ErrorIs(t, <-writeErr, context.Canceled)
 
-	expectedFile, err := testFs.Open("sox/pipe_silenced.wav")
-	require.NoError(t, err)
+	_, err = pipe.StdOut.Read(make([]byte, 1))
+	require.ErrorIs(t, err, context.Canceled)
+}
 
-	//compare results
-	statExpected, _ := expectedFile.Stat()
-	sizeExpected := statExpected.Size()
+func TestPipe_StartError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{
+		&passCommand{name: "first"},
+		brokenCommand{},
+	})
+	require.NoError(t, err)
 
-	require.Equalf(t, sizeExpected, sizeResAudio, "not expected file: %s", statResAudio.Name())
+	err = pipe.Start(context.Background())
+	require.EqualError(t, err, "broken: unable start")
+	require.EqualError(t, pipe.Wait(), "broken: unable start")
+	require.Error(t, pipe.Write([]byte{1}))
 }
@@ -0,0 +1,132 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PreRoll is a bounded in-memory pipe that takes audio from the first frame on,
+// before the reader is ready. Writes return at once until size bytes wait to be read,
+// then they block, nothing is dropped.
+type PreRoll struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	buf  bytes.Buffer
+	size int
+	werr error // set when the writer is closed, returned to the reader after buffered data
+	rerr error // set on Abort, returned to both sides
+}
+
+func NewPreRoll(size int) *PreRoll {
+	p := &PreRoll{size: size}
+	p.cond = sync.NewCond(&p.mu)
+
+	return p
+}
+
+func (p *PreRoll) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	written := 0
+
+	for len(b) > 0 {
+		for p.buf.Len() >= p.size && p.rerr == nil && p.werr == nil {
+			p.cond.Wait()
+		}
+
+		if p.rerr != nil {
+			return written, p.rerr
+		}
+
+		if p.werr != nil {
+			return written, io.ErrClosedPipe
+		}
+
+		n := p.size - p.buf.Len()
+		if n > len(b) {
+			n = len(b)
+		}
+
+		p.buf.Write(b[:n])
+		b = b[n:]
+		written += n
+
+		p.cond.Broadcast()
+	}
+
+	return written, nil
+}
+
+func (p *PreRoll) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.buf.Len() == 0 && p.rerr == nil && p.werr == nil {
+		p.cond.Wait()
+	}
+
+	if p.rerr != nil {
+		return 0, p.rerr
+	}
+
+	if p.buf.Len() == 0 {
+		return 0, p.werr
+	}
+
+	n, _ := p.buf.Read(b)
+
+	p.cond.Broadcast()
+
+	return n, nil
+}
+
+// Close ends the input, the reader gets io.EOF after the buffered data.
+func (p *PreRoll) Close() error {
+	return p.CloseWithError(nil)
+}
+
+// CloseWithError ends the input, the reader gets err after the buffered data.
+func (p *PreRoll) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.werr == nil {
+		p.werr = err
+	}
+
+	p.cond.Broadcast()
+
+	return nil
+}
+
+// Abort drops the buffered data, both sides get err.
+func (p *PreRoll) Abort(err error) {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rerr == nil {
+		p.rerr = err
+	}
+
+	p.buf.Reset()
+	p.cond.Broadcast()
+}
+
+// Buffered returns the size of data waiting to be read.
+func (p *PreRoll) Buffered() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.buf.Len()
+}
@@ -0,0 +1,125 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	testdata "github.com/Arten331/bot-checker/data/test"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	commands2 "github.com/Arten331/bot-checker/pkg/audio/commands"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// TestPreRoll_NoDrop feeds frames from the first one while the pipe is not started yet,
+// then with a full buffer, and checks every sample reaches the pipe output.
+func TestPreRoll_NoDrop(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	raw, err := testdata.GetTestFS().ReadFile("sox/raw_from_fork.pcm")
+	require.NoError(t, err)
+
+	const frame = 320 // 20 ms of AudioFork slin
+
+	preroll := audio.NewPreRoll(8000) // half a second
+
+	written := make(chan struct{})
+	prerolled := make(chan struct{})
+
+	go func() {
+		defer close(written)
+
+		for i := 0; i < len(raw); i += frame {
+			if i == 8000 {
+				close(prerolled)
+			}
+
+			end := i + frame
+			if end > len(raw) {
+				end = len(raw)
+			}
+
+			_, err := preroll.Write(raw[i:end])
+			if err != nil {
+				return
+			}
+		}
+
+		_ = preroll.Close()
+	}()
+
+	// the pipe comes up after the buffer is full and the writer blocks
+	<-prerolled
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 8000, preroll.Buffered())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pipe, err := audio.NewPipe([]audio.PipeCommand{commands2.NewPcmRawToWavNative(8000)})
+	require.NoError(t, err)
+	require.NoError(t, pipe.Start(ctx))
+
+	go func() {
+		_, _ = io.Copy(pipe.StdIn, preroll)
+		_ = pipe.Close()
+	}()
+
+	var result bytes.Buffer
+
+	_, err = io.Copy(&result, pipe.StdOut)
+	require.NoError(t, err)
+	require.NoError(t, pipe.Wait())
+
+	<-written
+
+	require.Equal(t, raw, result.Bytes()[audio.WavHeaderSize:])
+}
+
+func TestPreRoll_Abort(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	preroll := audio.NewPreRoll(4)
+	errAborted := errors.New("aborted")
+
+	writeErr := make(chan error, 1)
+
+	go func() {
+		_, err := preroll.Write([]byte("more than four bytes"))
+		writeErr <- err
+	}()
+
+	buf := make([]byte, 2)
+	n, err := preroll.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "mo", string(buf[:n]))
+
+	preroll.Abort(errAborted)
+
+	require.ErrorIs(t, <-writeErr, errAborted)
+
+	_, err = preroll.Read(buf)
+	require.ErrorIs(t, err, errAborted)
+}
+
+func TestPreRoll_CloseWithError(t *testing.T) {
+	preroll := audio.NewPreRoll(16)
+	errStream := errors.New("stream failed")
+
+	_, err := preroll.Write([]byte("tail"))
+	require.NoError(t, err)
+	require.NoError(t, preroll.CloseWithError(errStream))
+
+	_, err = preroll.Write([]byte("late"))
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+
+	data, err := io.ReadAll(preroll)
+	require.ErrorIs(t, err, errStream)
+	require.Equal(t, "tail", string(data))
+}
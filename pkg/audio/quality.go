@@ -0,0 +1,118 @@
+package audio
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	qualityClipLevel = math.MaxInt16 - 1 // samples at full scale either way
+	qualitySilenceDB = vadMinEnergyDB
+)
+
+// QualityStats describe the audio of a whole stream.
+type QualityStats struct {
+	RMSDB         float64 // average level in dBFS
+	PeakDB        float64
+	ClippingRatio float64 // share of samples at full scale
+	SilenceRatio  float64 // share of frames below the VAD energy floor
+	Speech        time.Duration
+	Duration      time.Duration
+}
+
+// QualityMeter is a Detector that reports no events and measures the stream for Stats.
+// Speech is counted by a VAD of its own, independent of the one gating the pipe.
+type QualityMeter struct {
+	mu sync.Mutex
+
+	vad         *VAD
+	sumSquares  float64
+	samples     int
+	peak        int
+	clipped     int
+	frames      int
+	silent      int
+	speech      time.Duration
+	speechStart time.Duration
+}
+
+func NewQualityMeter() *QualityMeter {
+	return &QualityMeter{vad: NewVAD(VADOptions{})}
+}
+
+func (q *QualityMeter) Detect(frame []int16, at time.Duration) (Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var frameSum float64
+
+	for _, s := range frame {
+		v := int(s)
+		if v < 0 {
+			v = -v
+		}
+
+		if v > q.peak {
+			q.peak = v
+		}
+
+		if v >= qualityClipLevel {
+			q.clipped++
+		}
+
+		frameSum += float64(s) * float64(s)
+	}
+
+	q.sumSquares += frameSum
+	q.samples += len(frame)
+	q.frames++
+
+	if len(frame) > 0 && dBFS(math.Sqrt(frameSum/float64(len(frame)))) < qualitySilenceDB {
+		q.silent++
+	}
+
+	if ev, ok := q.vad.Detect(frame, at); ok {
+		switch ev.Type { //nolint:exhaustive // the VAD reports speech events only
+		case EventSpeechStart:
+			q.speechStart = ev.At
+		case EventSpeechEnd:
+			q.speech += ev.Duration
+		}
+	}
+
+	return Event{}, false
+}
+
+// Stats returns the measures of the stream so far.
+func (q *QualityMeter) Stats() QualityStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := QualityStats{
+		RMSDB:    math.Inf(-1),
+		PeakDB:   dBFS(float64(q.peak)),
+		Speech:   q.speech,
+		Duration: time.Duration(q.frames) * FrameDuration,
+	}
+
+	if q.samples > 0 {
+		stats.RMSDB = dBFS(math.Sqrt(q.sumSquares / float64(q.samples)))
+		stats.ClippingRatio = float64(q.clipped) / float64(q.samples)
+	}
+
+	if q.frames > 0 {
+		stats.SilenceRatio = float64(q.silent) / float64(q.frames)
+	}
+
+	if q.vad.Speaking() {
+		stats.Speech += stats.Duration - q.speechStart
+	}
+
+	return stats
+}
+
+// dBFS converts an amplitude to the level relative to full scale, -inf for zero.
+func dBFS(amplitude float64) float64 {
+	return 20 * math.Log10(amplitude/math.MaxInt16)
+}
@@ -0,0 +1,49 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityMeter(t *testing.T) {
+	samples := synth(4*time.Second,
+		[2]time.Duration{500 * time.Millisecond, 1500 * time.Millisecond},
+		[2]time.Duration{2500 * time.Millisecond, 3 * time.Second},
+	)
+
+	meter := audio.NewQualityMeter()
+	require.Empty(t, detectAll(meter, samples))
+
+	stats := meter.Stats()
+	require.Equal(t, 4*time.Second, stats.Duration)
+	require.InDelta(t, 1500*time.Millisecond, stats.Speech, float64(4*audio.FrameDuration))
+	require.InDelta(t, 0.625, stats.SilenceRatio, 0.02) // 2.5 s of the low noise
+
+	// 300 Hz tone of 8000 amplitude for 1.5 s of 4 s
+	require.InDelta(t, 20*math.Log10(8000/math.Sqrt2/math.MaxInt16*math.Sqrt(1.5/4)), stats.RMSDB, 0.5)
+	require.InDelta(t, 20*math.Log10(8020.0/math.MaxInt16), stats.PeakDB, 0.1)
+	require.Zero(t, stats.ClippingRatio)
+}
+
+func TestQualityMeter_Clipping(t *testing.T) {
+	// a tone overdriven twice above full scale
+	samples := make([]int16, testSampleRate)
+	for i := range samples {
+		v := 2 * math.MaxInt16 * math.Sin(2*math.Pi*440*float64(i)/testSampleRate)
+		samples[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))
+	}
+
+	meter := audio.NewQualityMeter()
+	detectAll(meter, samples)
+
+	stats := meter.Stats()
+	require.InDelta(t, 2.0/3, stats.ClippingRatio, 0.05) // |sin| above 1/2 for two thirds of a period
+	require.InDelta(t, 0, stats.PeakDB, 0.01)
+	require.InDelta(t, time.Second, stats.Speech, float64(4*audio.FrameDuration))
+}
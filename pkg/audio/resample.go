@@ -0,0 +1,67 @@
+package audio
+
+import "math"
+
+// Resampler converts a stream of mono samples between sample rates with linear interpolation.
+// When downsampling the input is smoothed by a moving average first to limit aliasing.
+type Resampler struct {
+	step float64 // input samples per output sample
+
+	t       float64 // position of the next output sample, 0 is last
+	last    int16
+	started bool
+
+	avg    []int32
+	avgPos int
+	avgSum int32
+}
+
+func NewResampler(from, to int) *Resampler {
+	r := &Resampler{step: float64(from) / float64(to)}
+
+	if width := int(math.Round(r.step)); width > 1 {
+		r.avg = make([]int32, width)
+	}
+
+	return r
+}
+
+// Process appends the resampled in to out.
+func (r *Resampler) Process(in []int16, out []int16) []int16 {
+	if r.step == 1 && r.avg == nil {
+		return append(out, in...)
+	}
+
+	for _, v := range in {
+		v = r.smooth(v)
+
+		if !r.started {
+			r.started = true
+			r.last = v
+
+			continue
+		}
+
+		// output samples between last and v
+		for ; r.t < 1; r.t += r.step {
+			out = append(out, int16(math.Round(float64(r.last)+(float64(v)-float64(r.last))*r.t)))
+		}
+
+		r.t--
+		r.last = v
+	}
+
+	return out
+}
+
+func (r *Resampler) smooth(v int16) int16 {
+	if r.avg == nil {
+		return v
+	}
+
+	r.avgSum += int32(v) - r.avg[r.avgPos]
+	r.avg[r.avgPos] = int32(v)
+	r.avgPos = (r.avgPos + 1) % len(r.avg)
+
+	return int16(r.avgSum / int32(len(r.avg)))
+}
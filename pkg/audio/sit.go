@@ -0,0 +1,153 @@
+package audio
+
+import "time"
+
+const EventSIT EventType = "sit"
+
+// SIT variants, the event label.
+const (
+	SITIntercept   = "intercept"
+	SITVacantCode  = "vacant_code"
+	SITNoCircuit   = "no_circuit"
+	SITReorder     = "reorder"
+	SITIneffective = "ineffective_other"
+)
+
+const (
+	sitMinSegment = 200 * time.Millisecond
+	sitMaxSegment = 440 * time.Millisecond
+	sitLong       = 330 * time.Millisecond // between short 276 ms and long 380 ms segments
+	sitConfirm    = 100 * time.Millisecond // of the third tone
+	sitMaxGap     = 60 * time.Millisecond
+)
+
+// sitFrequencies of the three SIT segments: two variants for the first and second, one for the third.
+var sitFrequencies = [...]float64{913.8, 985.2, 1370.6, 1428.5, 1776.7}
+
+// sitStage maps a sitFrequencies index to the segment it belongs to.
+var sitStage = [...]int{0, 0, 1, 1, 2}
+
+type sitTone struct {
+	freq int
+	long bool
+}
+
+var sitVariants = map[[2]sitTone]string{
+	{{0, false}, {2, false}}: SITIntercept,
+	{{1, true}, {2, false}}:  SITVacantCode,
+	{{1, true}, {3, true}}:   SITNoCircuit,
+	{{0, true}, {2, true}}:   SITNoCircuit,
+	{{0, false}, {3, true}}:  SITReorder,
+	{{1, false}, {2, true}}:  SITReorder,
+	{{0, true}, {3, false}}:  SITIneffective,
+}
+
+type SITOptions struct {
+	ThresholdDB float64
+}
+
+// SITDetector reports the Special Information Tone that precedes carrier announcements,
+// classified by frequencies and lengths of its first two segments.
+type SITDetector struct {
+	sampleRate  int
+	thresholdDB float64
+
+	tone     int // index in sitFrequencies, -1 without a tone
+	start    time.Duration
+	length   time.Duration
+	misses   int
+	toneEnd  time.Duration
+	segments []sitTone
+	first    time.Duration
+	reported bool
+}
+
+func NewSITDetector(sampleRate int, o SITOptions) *SITDetector {
+	return &SITDetector{
+		sampleRate:  sampleRate,
+		thresholdDB: o.ThresholdDB,
+		tone:        -1,
+	}
+}
+
+func (d *SITDetector) Detect(frame []int16, at time.Duration) (Event, bool) {
+	tone := d.dominant(frame)
+
+	switch {
+	case tone >= 0 && tone == d.tone:
+		d.length = at + FrameDuration - d.start
+		d.misses = 0
+	case tone >= 0:
+		d.closeSegment()
+		d.tone, d.start, d.length, d.misses = tone, at, FrameDuration, 0
+	case d.tone >= 0:
+		d.misses++
+		if d.misses > toneMisses {
+			d.closeSegment()
+		}
+	case at-d.toneEnd > sitMaxGap:
+		d.segments = d.segments[:0]
+		d.reported = false
+	}
+
+	return d.classify(at)
+}
+
+// dominant returns the SIT frequency that forms a pure tone in frame, -1 if there is none.
+func (d *SITDetector) dominant(frame []int16) int {
+	best, bestPurity := -1, tonePurity
+
+	for i, freq := range sitFrequencies {
+		purity, level := ToneMeasure(frame, freq, d.sampleRate)
+		if purity >= bestPurity && level >= d.thresholdDB {
+			best, bestPurity = i, purity
+		}
+	}
+
+	return best
+}
+
+// closeSegment ends the current tone and appends it to the sequence if it continues it.
+func (d *SITDetector) closeSegment() {
+	if d.tone < 0 {
+		return
+	}
+
+	stage := sitStage[d.tone]
+	valid := d.length >= sitMinSegment && d.length <= sitMaxSegment
+
+	if !valid || stage != len(d.segments) || stage == 2 {
+		d.segments = d.segments[:0]
+	}
+
+	if valid && stage == len(d.segments) && stage < 2 {
+		if stage == 0 {
+			d.first = d.start
+		}
+
+		d.segments = append(d.segments, sitTone{freq: d.tone, long: d.length >= sitLong})
+	}
+
+	d.toneEnd = d.start + d.length
+	d.tone, d.length, d.misses = -1, 0, 0
+}
+
+func (d *SITDetector) classify(at time.Duration) (Event, bool) {
+	if d.reported || len(d.segments) != 2 || d.tone < 0 || sitStage[d.tone] != 2 || d.length < sitConfirm {
+		return Event{}, false
+	}
+
+	variant, ok := sitVariants[[2]sitTone{d.segments[0], d.segments[1]}]
+	if !ok {
+		return Event{}, false
+	}
+
+	d.reported = true
+
+	return Event{
+		Type:     EventSIT,
+		At:       d.first,
+		Duration: at + FrameDuration - d.first,
+		Label:    variant,
+	}, true
+}
@@ -0,0 +1,83 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sitShort = 276 * time.Millisecond
+	sitLong  = 380 * time.Millisecond
+)
+
+type sitSegment struct {
+	freq   float64
+	length time.Duration
+}
+
+// sitSignal builds one second of noise followed by the tone segments and a silent tail.
+func sitSignal(segments ...sitSegment) []int16 {
+	samples := synth(3 * time.Second)
+	at := time.Second
+
+	for _, s := range segments {
+		addTone(samples, s.freq, 4000, at, at+s.length)
+		at += s.length
+	}
+
+	return samples
+}
+
+func TestSITDetector(t *testing.T) {
+	tests := []struct {
+		variant  string
+		segments []sitSegment
+	}{
+		{audio.SITIntercept, []sitSegment{{913.8, sitShort}, {1370.6, sitShort}, {1776.7, sitLong}}},
+		{audio.SITVacantCode, []sitSegment{{985.2, sitLong}, {1370.6, sitShort}, {1776.7, sitLong}}},
+		{audio.SITNoCircuit, []sitSegment{{985.2, sitLong}, {1428.5, sitLong}, {1776.7, sitLong}}},
+		{audio.SITNoCircuit, []sitSegment{{913.8, sitLong}, {1370.6, sitLong}, {1776.7, sitLong}}},
+		{audio.SITReorder, []sitSegment{{913.8, sitShort}, {1428.5, sitLong}, {1776.7, sitLong}}},
+		{audio.SITReorder, []sitSegment{{985.2, sitShort}, {1370.6, sitLong}, {1776.7, sitLong}}},
+		{audio.SITIneffective, []sitSegment{{913.8, sitLong}, {1428.5, sitShort}, {1776.7, sitLong}}},
+	}
+
+	for _, tt := range tests {
+		events := detectAll(audio.NewSITDetector(testSampleRate, audio.SITOptions{ThresholdDB: -40}), sitSignal(tt.segments...))
+		require.Len(t, events, 1, tt.variant)
+
+		require.Equal(t, audio.EventSIT, events[0].Type)
+		require.Equal(t, tt.variant, events[0].Label)
+		require.InDelta(t, time.Second, events[0].At, float64(audio.FrameDuration))
+		// decided early in the third tone
+		require.Less(t, events[0].Duration, tt.segments[0].length+tt.segments[1].length+tt.segments[2].length/2)
+	}
+}
+
+func TestSITDetector_NotSIT(t *testing.T) {
+	tests := map[string][]sitSegment{
+		"wrong order":   {{1370.6, sitShort}, {913.8, sitShort}, {1776.7, sitLong}},
+		"too short":     {{913.8, 100 * time.Millisecond}, {1370.6, sitShort}, {1776.7, sitLong}},
+		"no third tone": {{913.8, sitShort}, {1370.6, sitShort}},
+		"beep":          {{1000, time.Second}},
+		// reserved for future use, not a variant
+		"reserved": {{985.2, sitShort}, {1428.5, sitShort}, {1776.7, sitLong}},
+	}
+
+	for name, segments := range tests {
+		events := detectAll(audio.NewSITDetector(testSampleRate, audio.SITOptions{ThresholdDB: -40}), sitSignal(segments...))
+		require.Empty(t, events, name)
+	}
+}
+
+func TestSITDetector_Records(t *testing.T) {
+	for _, name := range []string{"ABONENT_NE_MOJZHET.wav", "BLOCKED.wav", "BUSY_WAITING.wav", "DISCONNECTED1.wav", "SUBSCRIBER_NOT_AVAIL.wav"} {
+		events := detectAll(audio.NewSITDetector(testSampleRate, audio.SITOptions{ThresholdDB: -40}), readRecord(t, name))
+		require.Empty(t, events, name)
+	}
+}
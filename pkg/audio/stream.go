@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"context"
+	"io"
+)
+
+// StreamFunc consumes the whole stage input from r and writes the stage output to w.
+type StreamFunc func(r io.Reader, w io.Writer) error
+
+// Stream runs a StreamFunc as an in-process PipeCommand stage,
+// commands implemented in Go embed it and call Start from Handle.
+type Stream struct {
+	done chan struct{}
+	err  error
+}
+
+func (s *Stream) Start(ctx context.Context, fn StreamFunc) (in io.WriteCloser, out io.Reader) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	s.done = make(chan struct{})
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		select {
+		case <-ctx.Done():
+			_ = inR.CloseWithError(ctx.Err())
+			_ = outW.CloseWithError(ctx.Err())
+		case <-stop:
+		}
+	}()
+
+	go func() {
+		defer close(s.done)
+
+		s.err = fn(inR, outW)
+
+		// unblock the writer if fn returned before the end of input
+		if s.err != nil {
+			_ = inR.CloseWithError(s.err)
+		} else {
+			_ = inR.CloseWithError(io.ErrClosedPipe)
+		}
+
+		_ = outW.CloseWithError(s.err)
+
+		close(stop)
+		<-stopped
+	}()
+
+	return inW, outR
+}
+
+func (s *Stream) Wait() error {
+	if s.done == nil {
+		return nil
+	}
+
+	<-s.done
+
+	return s.err
+}
@@ -0,0 +1,159 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	vadStartFrames    = 3  // speech confirmed after 60 ms
+	vadHangoverFrames = 15 // speech ends after 300 ms of silence
+	vadMarginDB       = 9.0
+	vadLoudMarginDB   = 15.0
+	vadMinEnergyDB    = -50.0
+	vadMaxZCR         = 0.35
+	vadMinFloorDB     = -60.0
+	vadFloorRiseDB    = 0.05 // noise floor follows louder noise slowly
+)
+
+type VADOptions struct {
+	// SkipSilenceAfter closes the gate once silence lasts longer, zero keeps it always open.
+	SkipSilenceAfter time.Duration
+}
+
+// VAD is an energy and zero-crossing voice activity detector working on FrameDuration frames.
+// A frame is voiced when its energy is above the adaptive noise floor and the zero-crossing
+// rate is low enough for voice, or when it is much louder than the floor.
+type VAD struct {
+	options VADOptions
+
+	noiseFloor float64
+	speaking   bool
+	run        int
+	silent     int
+	startedAt  time.Duration
+	lastVoice  time.Duration
+	lastFrame  time.Duration
+}
+
+func NewVAD(o VADOptions) *VAD {
+	return &VAD{
+		options:    o,
+		noiseFloor: vadMinFloorDB,
+	}
+}
+
+func (v *VAD) Detect(frame []int16, at time.Duration) (Event, bool) {
+	if len(frame) == 0 {
+		return Event{}, false
+	}
+
+	energy, zcr := frameEnergy(frame)
+	voiced := v.voiced(energy, zcr)
+
+	if !voiced {
+		v.updateFloor(energy)
+	}
+
+	v.lastFrame = at
+
+	if !v.speaking {
+		if !voiced {
+			v.run = 0
+
+			return Event{}, false
+		}
+
+		if v.run == 0 {
+			v.startedAt = at
+		}
+
+		v.run++
+		if v.run < vadStartFrames {
+			return Event{}, false
+		}
+
+		v.speaking = true
+		v.silent = 0
+		v.lastVoice = at
+
+		return Event{Type: EventSpeechStart, At: v.startedAt}, true
+	}
+
+	if voiced {
+		v.silent = 0
+		v.lastVoice = at
+
+		return Event{}, false
+	}
+
+	v.silent++
+	if v.silent < vadHangoverFrames {
+		return Event{}, false
+	}
+
+	v.speaking = false
+	v.run = 0
+
+	end := v.lastVoice + FrameDuration
+
+	return Event{Type: EventSpeechEnd, At: end, Duration: end - v.startedAt}, true
+}
+
+// Open reports whether audio should be passed, it closes after SkipSilenceAfter of silence.
+func (v *VAD) Open() bool {
+	if v.options.SkipSilenceAfter <= 0 || v.speaking || v.run > 0 {
+		return true
+	}
+
+	return v.lastFrame-v.lastVoice < v.options.SkipSilenceAfter
+}
+
+func (v *VAD) Speaking() bool {
+	return v.speaking
+}
+
+func (v *VAD) voiced(energy, zcr float64) bool {
+	if energy < vadMinEnergyDB {
+		return false
+	}
+
+	if energy > v.noiseFloor+vadLoudMarginDB {
+		return true
+	}
+
+	return energy > v.noiseFloor+vadMarginDB && zcr < vadMaxZCR
+}
+
+func (v *VAD) updateFloor(energy float64) {
+	if energy < v.noiseFloor {
+		v.noiseFloor = math.Max(energy, vadMinFloorDB)
+
+		return
+	}
+
+	v.noiseFloor += vadFloorRiseDB
+}
+
+// frameEnergy returns the frame rms level in dBFS and the zero-crossing rate per sample.
+func frameEnergy(frame []int16) (energy, zcr float64) {
+	var (
+		sum       float64
+		crossings int
+	)
+
+	for i, s := range frame {
+		sum += float64(s) * float64(s)
+
+		if i > 0 && (s >= 0) != (frame[i-1] >= 0) {
+			crossings++
+		}
+	}
+
+	rms := math.Sqrt(sum / float64(len(frame)))
+	if rms < 1 {
+		rms = 1
+	}
+
+	return 20 * math.Log10(rms/math.MaxInt16), float64(crossings) / float64(len(frame))
+}
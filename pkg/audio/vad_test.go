@@ -0,0 +1,132 @@
+//go:build test && !integration
+
+package audio_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	testdata "github.com/Arten331/bot-checker/data/test"
+	"github.com/Arten331/bot-checker/pkg/audio"
+	"github.com/stretchr/testify/require"
+)
+
+const testSampleRate = 8000
+
+// synth builds pcm of low noise with tone bursts between the given offsets.
+func synth(total time.Duration, bursts ...[2]time.Duration) []int16 {
+	samples := make([]int16, int(total.Seconds()*testSampleRate))
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := range samples {
+		samples[i] = int16(rnd.Intn(41) - 20)
+	}
+
+	for _, b := range bursts {
+		for i := int(b[0].Seconds() * testSampleRate); i < int(b[1].Seconds()*testSampleRate); i++ {
+			samples[i] += int16(8000 * math.Sin(2*math.Pi*300*float64(i)/testSampleRate))
+		}
+	}
+
+	return samples
+}
+
+func detectAll(d audio.Detector, samples []int16) []audio.Event {
+	var (
+		events []audio.Event
+		at     time.Duration
+	)
+
+	frame := audio.FrameSamples(testSampleRate)
+
+	for i := 0; i+frame <= len(samples); i += frame {
+		if ev, ok := d.Detect(samples[i:i+frame], at); ok {
+			events = append(events, ev)
+		}
+
+		at += audio.FrameDuration
+	}
+
+	return events
+}
+
+func TestVAD(t *testing.T) {
+	samples := synth(4*time.Second,
+		[2]time.Duration{500 * time.Millisecond, 1500 * time.Millisecond},
+		[2]time.Duration{2500 * time.Millisecond, 3 * time.Second},
+	)
+
+	events := detectAll(audio.NewVAD(audio.VADOptions{}), samples)
+	require.Len(t, events, 4)
+
+	require.Equal(t, audio.EventSpeechStart, events[0].Type)
+	require.InDelta(t, 500*time.Millisecond, events[0].At, float64(audio.FrameDuration))
+
+	require.Equal(t, audio.EventSpeechEnd, events[1].Type)
+	require.InDelta(t, 1500*time.Millisecond, events[1].At, float64(audio.FrameDuration))
+	require.InDelta(t, time.Second, events[1].Duration, float64(2*audio.FrameDuration))
+
+	require.Equal(t, audio.EventSpeechStart, events[2].Type)
+	require.InDelta(t, 2500*time.Millisecond, events[2].At, float64(audio.FrameDuration))
+	require.Equal(t, audio.EventSpeechEnd, events[3].Type)
+}
+
+func TestVAD_Gate(t *testing.T) {
+	vad := audio.NewVAD(audio.VADOptions{SkipSilenceAfter: time.Second})
+	samples := synth(3*time.Second, [2]time.Duration{1500 * time.Millisecond, 2 * time.Second})
+	frame := audio.FrameSamples(testSampleRate)
+
+	var at time.Duration
+
+	open := map[time.Duration]bool{}
+
+	for i := 0; i+frame <= len(samples); i += frame {
+		vad.Detect(samples[i:i+frame], at)
+		open[at] = vad.Open()
+		at += audio.FrameDuration
+	}
+
+	require.True(t, open[500*time.Millisecond])
+	require.False(t, open[1200*time.Millisecond])
+	require.True(t, open[1600*time.Millisecond])
+	require.True(t, open[2800*time.Millisecond])
+}
+
+// readRecord returns the samples of a test record.
+func readRecord(t *testing.T, name string) []int16 {
+	t.Helper()
+
+	file, err := testdata.GetTestFS().Open("records/" + name)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	format, err := audio.ReadWavHeader(file)
+	require.NoError(t, err)
+	require.Equal(t, testSampleRate, format.SampleRate)
+
+	stat, err := file.Stat()
+	require.NoError(t, err)
+
+	raw := make([]byte, stat.Size()-audio.WavHeaderSize)
+	_, err = file.Read(raw)
+	require.NoError(t, err)
+
+	samples := make([]int16, len(raw)/2)
+	for i := range samples {
+		samples[i] = int16(uint16(raw[2*i]) | uint16(raw[2*i+1])<<8)
+	}
+
+	return samples
+}
+
+func TestVAD_Record(t *testing.T) {
+	samples := readRecord(t, "SUBSCRIBER_NOT_AVAIL.wav")
+
+	events := detectAll(audio.NewVAD(audio.VADOptions{}), samples)
+	require.NotEmpty(t, events)
+	require.Equal(t, audio.EventSpeechStart, events[0].Type)
+	require.Less(t, events[0].At, 300*time.Millisecond)
+}
@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	WavHeaderSize = 44
+	// WavStreamDataSize is the data length sox writes when the stream length is unknown.
+	WavStreamDataSize = 0x7ffff000
+)
+
+var ErrWavHeader = errors.New("invalid wav header")
+
+type WavFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+// WavHeader builds a canonical PCM wav header for dataSize bytes of samples.
+func WavHeader(f WavFormat, dataSize uint32) []byte {
+	blockAlign := f.Channels * f.BitsPerSample / 8
+
+	h := make([]byte, WavHeaderSize)
+
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], dataSize+WavHeaderSize-8)
+	copy(h[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1)
+	binary.LittleEndian.PutUint16(h[22:], uint16(f.Channels))
+	binary.LittleEndian.PutUint32(h[24:], uint32(f.SampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(f.SampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(h[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(h[34:], uint16(f.BitsPerSample))
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], dataSize)
+
+	return h
+}
+
+// ReadWavHeader consumes a wav header up to the start of the data chunk.
+func ReadWavHeader(r io.Reader) (WavFormat, error) {
+	var (
+		f     WavFormat
+		riff  [12]byte
+		chunk [8]byte
+	)
+
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return f, err
+	}
+
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return f, ErrWavHeader
+	}
+
+	for {
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return f, err
+		}
+
+		size := binary.LittleEndian.Uint32(chunk[4:])
+
+		switch string(chunk[0:4]) {
+		case "data":
+			if f.SampleRate == 0 {
+				return f, ErrWavHeader
+			}
+
+			return f, nil
+		case "fmt ":
+			if size < 16 {
+				return f, ErrWavHeader
+			}
+
+			body := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return f, err
+			}
+
+			f.Channels = int(binary.LittleEndian.Uint16(body[2:]))
+			f.SampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			f.BitsPerSample = int(binary.LittleEndian.Uint16(body[14:]))
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size+size%2)); err != nil {
+				return f, err
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package audiosocket reads and writes the frames of the Asterisk AudioSocket protocol.
+//
+// A frame is a kind byte, a big endian payload length and the payload.
+// Audio is signed linear, 16 bit little endian, 8kHz mono.
+package audiosocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type Kind byte
+
+const (
+	KindHangup Kind = 0x00
+	KindID     Kind = 0x01
+	KindDTMF   Kind = 0x03
+	KindAudio  Kind = 0x10
+	KindError  Kind = 0xff
+)
+
+const (
+	headerSize = 3
+	idSize     = 16
+
+	// MaxPayload limits frames read, asterisk sends 20ms of audio per frame.
+	MaxPayload = 1 << 14
+)
+
+// ErrorCode is the payload of an error frame.
+type ErrorCode byte
+
+const (
+	ErrorNone   ErrorCode = 0x00
+	ErrorHangup ErrorCode = 0x01
+	ErrorFrame  ErrorCode = 0x02
+	ErrorMemory ErrorCode = 0x04
+)
+
+var ErrFrameTooLarge = errors.New("audiosocket: frame too large")
+
+type Frame struct {
+	Kind    Kind
+	Payload []byte
+}
+
+// ReadFrame reads the next frame, its payload is reused from buf when it fits.
+func ReadFrame(r io.Reader, buf []byte) (Frame, error) {
+	var header [headerSize]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Frame{}, err
+	}
+
+	f := Frame{Kind: Kind(header[0])}
+
+	size := int(binary.BigEndian.Uint16(header[1:]))
+	if size > MaxPayload {
+		return f, ErrFrameTooLarge
+	}
+
+	if cap(buf) < size {
+		buf = make([]byte, size)
+	}
+
+	f.Payload = buf[:size]
+
+	if _, err := io.ReadFull(r, f.Payload); err != nil {
+		return f, unexpectedEOF(err)
+	}
+
+	return f, nil
+}
+
+// WriteFrame writes a frame of kind with payload in a single write.
+func WriteFrame(w io.Writer, kind Kind, payload []byte) error {
+	if len(payload) > 0xffff {
+		return ErrFrameTooLarge
+	}
+
+	frame := make([]byte, headerSize+len(payload))
+	frame[0] = byte(kind)
+	binary.BigEndian.PutUint16(frame[1:], uint16(len(payload)))
+	copy(frame[headerSize:], payload)
+
+	_, err := w.Write(frame)
+
+	return err
+}
+
+// ID is the UUID carried by an id frame in its canonical text form.
+func (f Frame) ID() (string, error) {
+	if f.Kind != KindID || len(f.Payload) != idSize {
+		return "", fmt.Errorf("audiosocket: not an id frame: kind %#x, %d bytes", byte(f.Kind), len(f.Payload))
+	}
+
+	p := f.Payload
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", p[0:4], p[4:6], p[6:8], p[8:10], p[10:16]), nil
+}
+
+// ErrorCode is the code carried by an error frame.
+func (f Frame) ErrorCode() ErrorCode {
+	if f.Kind != KindError || len(f.Payload) == 0 {
+		return ErrorNone
+	}
+
+	return ErrorCode(f.Payload[0])
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,66 @@
+//go:build test && !integration
+
+package audiosocket_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Arten331/bot-checker/pkg/audiosocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFrame(t *testing.T) {
+	stream := &bytes.Buffer{}
+
+	id := []byte{
+		0x40, 0x32, 0x5e, 0x1c, 0x9a, 0x7b, 0x4d, 0x2e,
+		0x8f, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd,
+	}
+
+	require.NoError(t, audiosocket.WriteFrame(stream, audiosocket.KindID, id))
+	require.NoError(t, audiosocket.WriteFrame(stream, audiosocket.KindAudio, make([]byte, 320)))
+	require.NoError(t, audiosocket.WriteFrame(stream, audiosocket.KindError, []byte{byte(audiosocket.ErrorFrame)}))
+	require.NoError(t, audiosocket.WriteFrame(stream, audiosocket.KindHangup, nil))
+
+	buf := make([]byte, 320)
+
+	f, err := audiosocket.ReadFrame(stream, buf)
+	require.NoError(t, err)
+
+	uuid, err := f.ID()
+	require.NoError(t, err)
+	require.Equal(t, "40325e1c-9a7b-4d2e-8f01-23456789abcd", uuid)
+
+	f, err = audiosocket.ReadFrame(stream, buf)
+	require.NoError(t, err)
+	require.Equal(t, audiosocket.KindAudio, f.Kind)
+	require.Len(t, f.Payload, 320)
+
+	f, err = audiosocket.ReadFrame(stream, buf)
+	require.NoError(t, err)
+	require.Equal(t, audiosocket.ErrorFrame, f.ErrorCode())
+
+	f, err = audiosocket.ReadFrame(stream, buf)
+	require.NoError(t, err)
+	require.Equal(t, audiosocket.KindHangup, f.Kind)
+	require.Empty(t, f.Payload)
+
+	_, err = audiosocket.ReadFrame(stream, buf)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadFrame_Invalid(t *testing.T) {
+	_, err := audiosocket.ReadFrame(bytes.NewReader([]byte{0x10, 0xff, 0xff}), nil)
+	require.ErrorIs(t, err, audiosocket.ErrFrameTooLarge)
+
+	_, err = audiosocket.ReadFrame(bytes.NewReader([]byte{0x10, 0x00, 0x04, 0x01}), nil)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	f, err := audiosocket.ReadFrame(bytes.NewReader([]byte{0x10, 0x00, 0x02, 0x01, 0x02}), nil)
+	require.NoError(t, err)
+
+	_, err = f.ID()
+	require.Error(t, err)
+}
@@ -17,9 +17,36 @@ const (
 	EOF      = "{\"eof\" : 1}"
 )
 
+const (
+	TransportWebsocket = "ws"
+	TransportGRPC      = "grpc"
+)
+
+const defaultSampleRate = 8000
+
+// Recognizer streams audio to a recognition backend and returns its results.
+type Recognizer interface {
+	ProcessAudio(ctx context.Context, reader io.Reader) (resultChannel chan models.KaldiMessage, errChannel chan error)
+}
+
 type Options struct {
-	Host string
-	Port int
+	Host       string
+	Port       int
+	Transport  string
+	GRPCPort   int
+	SampleRate int
+}
+
+// New builds the Recognizer for the configured transport, websocket by default.
+func New(o Options) (Recognizer, error) {
+	switch o.Transport {
+	case "", TransportWebsocket:
+		return NewClient(o), nil
+	case TransportGRPC:
+		return NewGRPCClient(o)
+	default:
+		return nil, fmt.Errorf("unknown kaldi transport %q", o.Transport)
+	}
 }
 
 type Client struct {
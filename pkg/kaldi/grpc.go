@@ -0,0 +1,190 @@
+package kaldi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative sttpb/stt_service.proto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/Arten331/bot-checker/internal/models"
+	"github.com/Arten331/bot-checker/pkg/kaldi/sttpb"
+	"github.com/Arten331/observability/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const wavHeaderSize = 44
+
+type GRPCClient struct {
+	conn       *grpc.ClientConn
+	sampleRate int64
+}
+
+func NewGRPCClient(o Options) (*GRPCClient, error) {
+	sampleRate := o.SampleRate
+	if sampleRate == 0 {
+		sampleRate = defaultSampleRate
+	}
+
+	conn, err := grpc.Dial(
+		net.JoinHostPort(o.Host, strconv.Itoa(o.GRPCPort)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCClient{
+		conn:       conn,
+		sampleRate: int64(sampleRate),
+	}, nil
+}
+
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *GRPCClient) ProcessAudio(ctx context.Context, reader io.Reader) (resultChannel chan models.KaldiMessage, errChannel chan error) {
+	resultChannel = make(chan models.KaldiMessage, 1)
+	errChannel = make(chan error, 1)
+
+	go func() {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := sttpb.NewSttServiceClient(c.conn).StreamingRecognize(ctx)
+		if err != nil {
+			sendErr(ctx, errChannel, err)
+
+			return
+		}
+
+		err = stream.Send(&sttpb.StreamingRecognitionRequest{
+			StreamingRequest: &sttpb.StreamingRecognitionRequest_Config{
+				Config: &sttpb.RecognitionConfig{
+					Specification: &sttpb.RecognitionSpec{
+						AudioEncoding:   sttpb.RecognitionSpec_LINEAR16_PCM,
+						SampleRateHertz: c.sampleRate,
+						PartialResults:  true,
+					},
+				},
+			},
+		})
+		if err != nil {
+			sendErr(ctx, errChannel, err)
+
+			return
+		}
+
+		go func() {
+			err := c.writeAudio(ctx, stream, reader)
+			if err != nil {
+				sendErr(ctx, errChannel, err)
+			}
+		}()
+
+		err = c.readMessages(ctx, stream, resultChannel)
+		if err != nil {
+			sendErr(ctx, errChannel, err)
+		}
+	}()
+
+	return resultChannel, errChannel
+}
+
+func (c *GRPCClient) writeAudio(ctx context.Context, stream sttpb.SttService_StreamingRecognizeClient, reader io.Reader) error {
+	var headerChecked bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.L().Debug("ctx done, stop kaldi process")
+
+			return nil
+		default:
+			buf := make([]byte, BUFFSIZE)
+
+			n, errRead := reader.Read(buf)
+			chunk := buf[:n]
+
+			// LINEAR16_PCM expects bare samples, the sox pipe emits a wav stream
+			if !headerChecked && n > 0 {
+				headerChecked = true
+
+				if n >= wavHeaderSize && bytes.HasPrefix(chunk, []byte("RIFF")) {
+					chunk = chunk[wavHeaderSize:]
+				}
+			}
+
+			if len(chunk) > 0 {
+				err := stream.Send(&sttpb.StreamingRecognitionRequest{
+					StreamingRequest: &sttpb.StreamingRecognitionRequest_AudioContent{AudioContent: chunk},
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if errRead == io.EOF {
+				return stream.CloseSend()
+			}
+
+			if errRead != nil {
+				return errRead
+			}
+		}
+	}
+}
+
+func (c *GRPCClient) readMessages(
+	ctx context.Context,
+	stream sttpb.SttService_StreamingRecognizeClient,
+	ch chan<- models.KaldiMessage,
+) error {
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("kaldi grpc recv: %w", err)
+		}
+
+		for _, chunk := range resp.GetChunks() {
+			alternatives := chunk.GetAlternatives()
+			if len(alternatives) == 0 {
+				continue
+			}
+
+			message := models.KaldiMessage{
+				Text:    []byte(alternatives[0].GetText()),
+				IsFinal: chunk.GetFinal(),
+			}
+
+			select {
+			case ch <- message:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func sendErr(ctx context.Context, errChannel chan<- error, err error) {
+	select {
+	case errChannel <- err:
+	case <-ctx.Done():
+	}
+}
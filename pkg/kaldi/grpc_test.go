@@ -0,0 +1,70 @@
+//go:build test && !integration
+
+package kaldi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testdata "github.com/Arten331/bot-checker/data/test"
+	"github.com/Arten331/bot-checker/pkg/kaldi/kalditest"
+	"github.com/Arten331/bot-checker/pkg/kaldi/sttpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCClient(t *testing.T) {
+	const transcript = "абонент временно недоступен"
+
+	server, err := kalditest.NewGRPCServer(transcript)
+	require.NoError(t, err)
+
+	defer server.Close()
+
+	recognizer, err := New(Options{
+		Host:      "127.0.0.1",
+		Transport: TransportGRPC,
+		GRPCPort:  server.Port(),
+	})
+	require.NoError(t, err)
+
+	file, err := testdata.GetTestFS().Open("records/SUBSCRIBER_NOT_AVAIL.wav")
+	require.NoError(t, err)
+
+	defer func() { _ = file.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resCh, errCh := recognizer.ProcessAudio(ctx, file)
+
+	var partials int
+
+	for {
+		select {
+		case res := <-resCh:
+			if !res.IsFinal {
+				partials++
+
+				continue
+			}
+
+			require.Equal(t, transcript, string(res.Text))
+			require.Equal(t, 3, partials)
+
+			cfg := server.Config().GetSpecification()
+			require.Equal(t, sttpb.RecognitionSpec_LINEAR16_PCM, cfg.GetAudioEncoding())
+			require.EqualValues(t, defaultSampleRate, cfg.GetSampleRateHertz())
+
+			stat, err := file.Stat()
+			require.NoError(t, err)
+			require.EqualValues(t, stat.Size()-wavHeaderSize, server.AudioBytes())
+
+			return
+		case err = <-errCh:
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("no final result")
+		}
+	}
+}
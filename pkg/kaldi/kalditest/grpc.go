@@ -0,0 +1,119 @@
+// Package kalditest provides an in-process Vosk gRPC server for tests.
+package kalditest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/Arten331/bot-checker/pkg/kaldi/sttpb"
+	"google.golang.org/grpc"
+)
+
+// GRPCServer answers StreamingRecognize with a scripted transcript: every audio chunk
+// yields the next word as a partial result, the closing of the stream yields the final text.
+type GRPCServer struct {
+	sttpb.UnimplementedSttServiceServer
+
+	Transcript string
+
+	server   *grpc.Server
+	listener net.Listener
+
+	mu         sync.Mutex
+	config     *sttpb.RecognitionConfig
+	audioBytes int
+}
+
+func NewGRPCServer(transcript string) (*GRPCServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &GRPCServer{
+		Transcript: transcript,
+		server:     grpc.NewServer(),
+		listener:   listener,
+	}
+
+	sttpb.RegisterSttServiceServer(s.server, s)
+
+	go func() { _ = s.server.Serve(listener) }()
+
+	return s, nil
+}
+
+func (s *GRPCServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *GRPCServer) Close() {
+	s.server.Stop()
+}
+
+// Config returns the recognition config received with the last stream.
+func (s *GRPCServer) Config() *sttpb.RecognitionConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.config
+}
+
+// AudioBytes returns the amount of audio received over all streams.
+func (s *GRPCServer) AudioBytes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.audioBytes
+}
+
+func (s *GRPCServer) StreamingRecognize(stream sttpb.SttService_StreamingRecognizeServer) error {
+	words := strings.Fields(s.Transcript)
+	sent := 0
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.Send(response(s.Transcript, true))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if cfg := req.GetConfig(); cfg != nil {
+			s.mu.Lock()
+			s.config = cfg
+			s.mu.Unlock()
+
+			continue
+		}
+
+		s.mu.Lock()
+		s.audioBytes += len(req.GetAudioContent())
+		s.mu.Unlock()
+
+		if sent < len(words) {
+			sent++
+
+			err = stream.Send(response(strings.Join(words[:sent], " "), false))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func response(text string, final bool) *sttpb.StreamingRecognitionResponse {
+	return &sttpb.StreamingRecognitionResponse{
+		Chunks: []*sttpb.SpeechRecognitionChunk{
+			{
+				Alternatives: []*sttpb.SpeechRecognitionAlternative{{Text: text}},
+				Final:        final,
+			},
+		},
+	}
+}
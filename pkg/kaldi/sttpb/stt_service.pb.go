@@ -0,0 +1,702 @@
+// Vosk server gRPC API (vosk-server/grpc/stt_service.proto), trimmed to the
+// fields used by bot-checker.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: stt_service.proto
+
+package sttpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RecognitionSpec_AudioEncoding int32
+
+const (
+	RecognitionSpec_AUDIO_ENCODING_UNSPECIFIED RecognitionSpec_AudioEncoding = 0
+	RecognitionSpec_LINEAR16_PCM               RecognitionSpec_AudioEncoding = 1
+)
+
+// Enum value maps for RecognitionSpec_AudioEncoding.
+var (
+	RecognitionSpec_AudioEncoding_name = map[int32]string{
+		0: "AUDIO_ENCODING_UNSPECIFIED",
+		1: "LINEAR16_PCM",
+	}
+	RecognitionSpec_AudioEncoding_value = map[string]int32{
+		"AUDIO_ENCODING_UNSPECIFIED": 0,
+		"LINEAR16_PCM":               1,
+	}
+)
+
+func (x RecognitionSpec_AudioEncoding) Enum() *RecognitionSpec_AudioEncoding {
+	p := new(RecognitionSpec_AudioEncoding)
+	*p = x
+	return p
+}
+
+func (x RecognitionSpec_AudioEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecognitionSpec_AudioEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_stt_service_proto_enumTypes[0].Descriptor()
+}
+
+func (RecognitionSpec_AudioEncoding) Type() protoreflect.EnumType {
+	return &file_stt_service_proto_enumTypes[0]
+}
+
+func (x RecognitionSpec_AudioEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecognitionSpec_AudioEncoding.Descriptor instead.
+func (RecognitionSpec_AudioEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{3, 0}
+}
+
+type StreamingRecognitionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to StreamingRequest:
+	//	*StreamingRecognitionRequest_Config
+	//	*StreamingRecognitionRequest_AudioContent
+	StreamingRequest isStreamingRecognitionRequest_StreamingRequest `protobuf_oneof:"streaming_request"`
+}
+
+func (x *StreamingRecognitionRequest) Reset() {
+	*x = StreamingRecognitionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stt_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamingRecognitionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamingRecognitionRequest) ProtoMessage() {}
+
+func (x *StreamingRecognitionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stt_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamingRecognitionRequest.ProtoReflect.Descriptor instead.
+func (*StreamingRecognitionRequest) Descriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *StreamingRecognitionRequest) GetStreamingRequest() isStreamingRecognitionRequest_StreamingRequest {
+	if m != nil {
+		return m.StreamingRequest
+	}
+	return nil
+}
+
+func (x *StreamingRecognitionRequest) GetConfig() *RecognitionConfig {
+	if x, ok := x.GetStreamingRequest().(*StreamingRecognitionRequest_Config); ok {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *StreamingRecognitionRequest) GetAudioContent() []byte {
+	if x, ok := x.GetStreamingRequest().(*StreamingRecognitionRequest_AudioContent); ok {
+		return x.AudioContent
+	}
+	return nil
+}
+
+type isStreamingRecognitionRequest_StreamingRequest interface {
+	isStreamingRecognitionRequest_StreamingRequest()
+}
+
+type StreamingRecognitionRequest_Config struct {
+	Config *RecognitionConfig `protobuf:"bytes,1,opt,name=config,proto3,oneof"`
+}
+
+type StreamingRecognitionRequest_AudioContent struct {
+	AudioContent []byte `protobuf:"bytes,2,opt,name=audio_content,json=audioContent,proto3,oneof"`
+}
+
+func (*StreamingRecognitionRequest_Config) isStreamingRecognitionRequest_StreamingRequest() {}
+
+func (*StreamingRecognitionRequest_AudioContent) isStreamingRecognitionRequest_StreamingRequest() {}
+
+type StreamingRecognitionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chunks []*SpeechRecognitionChunk `protobuf:"bytes,1,rep,name=chunks,proto3" json:"chunks,omitempty"`
+}
+
+func (x *StreamingRecognitionResponse) Reset() {
+	*x = StreamingRecognitionResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stt_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamingRecognitionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamingRecognitionResponse) ProtoMessage() {}
+
+func (x *StreamingRecognitionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stt_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamingRecognitionResponse.ProtoReflect.Descriptor instead.
+func (*StreamingRecognitionResponse) Descriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StreamingRecognitionResponse) GetChunks() []*SpeechRecognitionChunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+type RecognitionConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Specification *RecognitionSpec `protobuf:"bytes,1,opt,name=specification,proto3" json:"specification,omitempty"`
+}
+
+func (x *RecognitionConfig) Reset() {
+	*x = RecognitionConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stt_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecognitionConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecognitionConfig) ProtoMessage() {}
+
+func (x *RecognitionConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_stt_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecognitionConfig.ProtoReflect.Descriptor instead.
+func (*RecognitionConfig) Descriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RecognitionConfig) GetSpecification() *RecognitionSpec {
+	if x != nil {
+		return x.Specification
+	}
+	return nil
+}
+
+type RecognitionSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AudioEncoding         RecognitionSpec_AudioEncoding `protobuf:"varint,1,opt,name=audio_encoding,json=audioEncoding,proto3,enum=vosk.stt.v1.RecognitionSpec_AudioEncoding" json:"audio_encoding,omitempty"`
+	SampleRateHertz       int64                         `protobuf:"varint,2,opt,name=sample_rate_hertz,json=sampleRateHertz,proto3" json:"sample_rate_hertz,omitempty"`
+	LanguageCode          string                        `protobuf:"bytes,3,opt,name=language_code,json=languageCode,proto3" json:"language_code,omitempty"`
+	ProfanityFilter       bool                          `protobuf:"varint,4,opt,name=profanity_filter,json=profanityFilter,proto3" json:"profanity_filter,omitempty"`
+	Model                 string                        `protobuf:"bytes,5,opt,name=model,proto3" json:"model,omitempty"`
+	PartialResults        bool                          `protobuf:"varint,7,opt,name=partial_results,json=partialResults,proto3" json:"partial_results,omitempty"`
+	SingleUtterance       bool                          `protobuf:"varint,8,opt,name=single_utterance,json=singleUtterance,proto3" json:"single_utterance,omitempty"`
+	MaxAlternatives       int64                         `protobuf:"varint,9,opt,name=max_alternatives,json=maxAlternatives,proto3" json:"max_alternatives,omitempty"`
+	EnableWordTimeOffsets bool                          `protobuf:"varint,10,opt,name=enable_word_time_offsets,json=enableWordTimeOffsets,proto3" json:"enable_word_time_offsets,omitempty"`
+}
+
+func (x *RecognitionSpec) Reset() {
+	*x = RecognitionSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stt_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecognitionSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecognitionSpec) ProtoMessage() {}
+
+func (x *RecognitionSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_stt_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecognitionSpec.ProtoReflect.Descriptor instead.
+func (*RecognitionSpec) Descriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RecognitionSpec) GetAudioEncoding() RecognitionSpec_AudioEncoding {
+	if x != nil {
+		return x.AudioEncoding
+	}
+	return RecognitionSpec_AUDIO_ENCODING_UNSPECIFIED
+}
+
+func (x *RecognitionSpec) GetSampleRateHertz() int64 {
+	if x != nil {
+		return x.SampleRateHertz
+	}
+	return 0
+}
+
+func (x *RecognitionSpec) GetLanguageCode() string {
+	if x != nil {
+		return x.LanguageCode
+	}
+	return ""
+}
+
+func (x *RecognitionSpec) GetProfanityFilter() bool {
+	if x != nil {
+		return x.ProfanityFilter
+	}
+	return false
+}
+
+func (x *RecognitionSpec) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *RecognitionSpec) GetPartialResults() bool {
+	if x != nil {
+		return x.PartialResults
+	}
+	return false
+}
+
+func (x *RecognitionSpec) GetSingleUtterance() bool {
+	if x != nil {
+		return x.SingleUtterance
+	}
+	return false
+}
+
+func (x *RecognitionSpec) GetMaxAlternatives() int64 {
+	if x != nil {
+		return x.MaxAlternatives
+	}
+	return 0
+}
+
+func (x *RecognitionSpec) GetEnableWordTimeOffsets() bool {
+	if x != nil {
+		return x.EnableWordTimeOffsets
+	}
+	return false
+}
+
+type SpeechRecognitionChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Alternatives   []*SpeechRecognitionAlternative `protobuf:"bytes,1,rep,name=alternatives,proto3" json:"alternatives,omitempty"`
+	Final          bool                            `protobuf:"varint,2,opt,name=final,proto3" json:"final,omitempty"`
+	EndOfUtterance bool                            `protobuf:"varint,3,opt,name=end_of_utterance,json=endOfUtterance,proto3" json:"end_of_utterance,omitempty"`
+}
+
+func (x *SpeechRecognitionChunk) Reset() {
+	*x = SpeechRecognitionChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stt_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SpeechRecognitionChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeechRecognitionChunk) ProtoMessage() {}
+
+func (x *SpeechRecognitionChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_stt_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeechRecognitionChunk.ProtoReflect.Descriptor instead.
+func (*SpeechRecognitionChunk) Descriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SpeechRecognitionChunk) GetAlternatives() []*SpeechRecognitionAlternative {
+	if x != nil {
+		return x.Alternatives
+	}
+	return nil
+}
+
+func (x *SpeechRecognitionChunk) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+func (x *SpeechRecognitionChunk) GetEndOfUtterance() bool {
+	if x != nil {
+		return x.EndOfUtterance
+	}
+	return false
+}
+
+type SpeechRecognitionAlternative struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text       string  `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Confidence float32 `protobuf:"fixed32,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+}
+
+func (x *SpeechRecognitionAlternative) Reset() {
+	*x = SpeechRecognitionAlternative{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stt_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SpeechRecognitionAlternative) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeechRecognitionAlternative) ProtoMessage() {}
+
+func (x *SpeechRecognitionAlternative) ProtoReflect() protoreflect.Message {
+	mi := &file_stt_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeechRecognitionAlternative.ProtoReflect.Descriptor instead.
+func (*SpeechRecognitionAlternative) Descriptor() ([]byte, []int) {
+	return file_stt_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SpeechRecognitionAlternative) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SpeechRecognitionAlternative) GetConfidence() float32 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+var File_stt_service_proto protoreflect.FileDescriptor
+
+var file_stt_service_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x73, 0x74, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x76, 0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74, 0x74, 0x2e, 0x76, 0x31,
+	0x22, 0x93, 0x01, 0x0a, 0x1b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x65,
+	0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x38, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x76, 0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x48, 0x00, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x25, 0x0a, 0x0d, 0x61, 0x75,
+	0x64, 0x69, 0x6f, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x0c, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x42, 0x13, 0x0a, 0x11, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5b, 0x0a, 0x1c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x76, 0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x52, 0x65, 0x63, 0x6f, 0x67,
+	0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52, 0x06, 0x63, 0x68, 0x75,
+	0x6e, 0x6b, 0x73, 0x22, 0x57, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x42, 0x0a, 0x0d, 0x73, 0x70, 0x65, 0x63,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1c, 0x2e, 0x76, 0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x70, 0x65, 0x63, 0x52, 0x0d, 0x73,
+	0x70, 0x65, 0x63, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xf1, 0x03, 0x0a,
+	0x0f, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x70, 0x65, 0x63,
+	0x12, 0x51, 0x0a, 0x0e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x5f, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x76, 0x6f, 0x73, 0x6b, 0x2e,
+	0x73, 0x74, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x70, 0x65, 0x63, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x52, 0x0d, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x45, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x72, 0x61,
+	0x74, 0x65, 0x5f, 0x68, 0x65, 0x72, 0x74, 0x7a, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f,
+	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x61, 0x74, 0x65, 0x48, 0x65, 0x72, 0x74, 0x7a, 0x12,
+	0x23, 0x0a, 0x0d, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65,
+	0x43, 0x6f, 0x64, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x66, 0x61, 0x6e, 0x69, 0x74,
+	0x79, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f,
+	0x70, 0x72, 0x6f, 0x66, 0x61, 0x6e, 0x69, 0x74, 0x79, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12,
+	0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c,
+	0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e,
+	0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x29,
+	0x0a, 0x10, 0x73, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x5f, 0x75, 0x74, 0x74, 0x65, 0x72, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73, 0x69, 0x6e, 0x67, 0x6c, 0x65,
+	0x55, 0x74, 0x74, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x61, 0x78,
+	0x5f, 0x61, 0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x73, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x41, 0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74,
+	0x69, 0x76, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x18, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x77,
+	0x6f, 0x72, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x73,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x15, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x57, 0x6f,
+	0x72, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x73, 0x22, 0x41, 0x0a,
+	0x0d, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1e,
+	0x0a, 0x1a, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x10,
+	0x0a, 0x0c, 0x4c, 0x49, 0x4e, 0x45, 0x41, 0x52, 0x31, 0x36, 0x5f, 0x50, 0x43, 0x4d, 0x10, 0x01,
+	0x22, 0xa7, 0x01, 0x0a, 0x16, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x52, 0x65, 0x63, 0x6f, 0x67,
+	0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x4d, 0x0a, 0x0c, 0x61,
+	0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x29, 0x2e, 0x76, 0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x41, 0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x52, 0x0c, 0x61, 0x6c,
+	0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69,
+	0x6e, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x69, 0x6e, 0x61, 0x6c,
+	0x12, 0x28, 0x0a, 0x10, 0x65, 0x6e, 0x64, 0x5f, 0x6f, 0x66, 0x5f, 0x75, 0x74, 0x74, 0x65, 0x72,
+	0x61, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x65, 0x6e, 0x64, 0x4f,
+	0x66, 0x55, 0x74, 0x74, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x52, 0x0a, 0x1c, 0x53, 0x70,
+	0x65, 0x65, 0x63, 0x68, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x41,
+	0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65,
+	0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x1e,
+	0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x02, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x32, 0x7d,
+	0x0a, 0x0a, 0x53, 0x74, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6f, 0x0a, 0x12,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69,
+	0x7a, 0x65, 0x12, 0x28, 0x2e, 0x76, 0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x76,
+	0x6f, 0x73, 0x6b, 0x2e, 0x73, 0x74, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x67, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x31, 0x5a,
+	0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x41, 0x72, 0x74, 0x65,
+	0x6e, 0x33, 0x33, 0x31, 0x2f, 0x62, 0x6f, 0x74, 0x2d, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x6b, 0x61, 0x6c, 0x64, 0x69, 0x2f, 0x73, 0x74, 0x74, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_stt_service_proto_rawDescOnce sync.Once
+	file_stt_service_proto_rawDescData = file_stt_service_proto_rawDesc
+)
+
+func file_stt_service_proto_rawDescGZIP() []byte {
+	file_stt_service_proto_rawDescOnce.Do(func() {
+		file_stt_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_stt_service_proto_rawDescData)
+	})
+	return file_stt_service_proto_rawDescData
+}
+
+var file_stt_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_stt_service_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_stt_service_proto_goTypes = []interface{}{
+	(RecognitionSpec_AudioEncoding)(0),   // 0: vosk.stt.v1.RecognitionSpec.AudioEncoding
+	(*StreamingRecognitionRequest)(nil),  // 1: vosk.stt.v1.StreamingRecognitionRequest
+	(*StreamingRecognitionResponse)(nil), // 2: vosk.stt.v1.StreamingRecognitionResponse
+	(*RecognitionConfig)(nil),            // 3: vosk.stt.v1.RecognitionConfig
+	(*RecognitionSpec)(nil),              // 4: vosk.stt.v1.RecognitionSpec
+	(*SpeechRecognitionChunk)(nil),       // 5: vosk.stt.v1.SpeechRecognitionChunk
+	(*SpeechRecognitionAlternative)(nil), // 6: vosk.stt.v1.SpeechRecognitionAlternative
+}
+var file_stt_service_proto_depIdxs = []int32{
+	3, // 0: vosk.stt.v1.StreamingRecognitionRequest.config:type_name -> vosk.stt.v1.RecognitionConfig
+	5, // 1: vosk.stt.v1.StreamingRecognitionResponse.chunks:type_name -> vosk.stt.v1.SpeechRecognitionChunk
+	4, // 2: vosk.stt.v1.RecognitionConfig.specification:type_name -> vosk.stt.v1.RecognitionSpec
+	0, // 3: vosk.stt.v1.RecognitionSpec.audio_encoding:type_name -> vosk.stt.v1.RecognitionSpec.AudioEncoding
+	6, // 4: vosk.stt.v1.SpeechRecognitionChunk.alternatives:type_name -> vosk.stt.v1.SpeechRecognitionAlternative
+	1, // 5: vosk.stt.v1.SttService.StreamingRecognize:input_type -> vosk.stt.v1.StreamingRecognitionRequest
+	2, // 6: vosk.stt.v1.SttService.StreamingRecognize:output_type -> vosk.stt.v1.StreamingRecognitionResponse
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_stt_service_proto_init() }
+func file_stt_service_proto_init() {
+	if File_stt_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_stt_service_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamingRecognitionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stt_service_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamingRecognitionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stt_service_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecognitionConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stt_service_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecognitionSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stt_service_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SpeechRecognitionChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stt_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SpeechRecognitionAlternative); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_stt_service_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*StreamingRecognitionRequest_Config)(nil),
+		(*StreamingRecognitionRequest_AudioContent)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_stt_service_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stt_service_proto_goTypes,
+		DependencyIndexes: file_stt_service_proto_depIdxs,
+		EnumInfos:         file_stt_service_proto_enumTypes,
+		MessageInfos:      file_stt_service_proto_msgTypes,
+	}.Build()
+	File_stt_service_proto = out.File
+	file_stt_service_proto_rawDesc = nil
+	file_stt_service_proto_goTypes = nil
+	file_stt_service_proto_depIdxs = nil
+}
@@ -0,0 +1,144 @@
+// Vosk server gRPC API (vosk-server/grpc/stt_service.proto), trimmed to the
+// fields used by bot-checker.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: stt_service.proto
+
+package sttpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SttService_StreamingRecognize_FullMethodName = "/vosk.stt.v1.SttService/StreamingRecognize"
+)
+
+// SttServiceClient is the client API for SttService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SttServiceClient interface {
+	StreamingRecognize(ctx context.Context, opts ...grpc.CallOption) (SttService_StreamingRecognizeClient, error)
+}
+
+type sttServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSttServiceClient(cc grpc.ClientConnInterface) SttServiceClient {
+	return &sttServiceClient{cc}
+}
+
+func (c *sttServiceClient) StreamingRecognize(ctx context.Context, opts ...grpc.CallOption) (SttService_StreamingRecognizeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SttService_ServiceDesc.Streams[0], SttService_StreamingRecognize_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sttServiceStreamingRecognizeClient{stream}
+	return x, nil
+}
+
+type SttService_StreamingRecognizeClient interface {
+	Send(*StreamingRecognitionRequest) error
+	Recv() (*StreamingRecognitionResponse, error)
+	grpc.ClientStream
+}
+
+type sttServiceStreamingRecognizeClient struct {
+	grpc.ClientStream
+}
+
+func (x *sttServiceStreamingRecognizeClient) Send(m *StreamingRecognitionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sttServiceStreamingRecognizeClient) Recv() (*StreamingRecognitionResponse, error) {
+	m := new(StreamingRecognitionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SttServiceServer is the server API for SttService service.
+// All implementations must embed UnimplementedSttServiceServer
+// for forward compatibility
+type SttServiceServer interface {
+	StreamingRecognize(SttService_StreamingRecognizeServer) error
+	mustEmbedUnimplementedSttServiceServer()
+}
+
+// UnimplementedSttServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSttServiceServer struct {
+}
+
+func (UnimplementedSttServiceServer) StreamingRecognize(SttService_StreamingRecognizeServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamingRecognize not implemented")
+}
+func (UnimplementedSttServiceServer) mustEmbedUnimplementedSttServiceServer() {}
+
+// UnsafeSttServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SttServiceServer will
+// result in compilation errors.
+type UnsafeSttServiceServer interface {
+	mustEmbedUnimplementedSttServiceServer()
+}
+
+func RegisterSttServiceServer(s grpc.ServiceRegistrar, srv SttServiceServer) {
+	s.RegisterService(&SttService_ServiceDesc, srv)
+}
+
+func _SttService_StreamingRecognize_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SttServiceServer).StreamingRecognize(&sttServiceStreamingRecognizeServer{stream})
+}
+
+type SttService_StreamingRecognizeServer interface {
+	Send(*StreamingRecognitionResponse) error
+	Recv() (*StreamingRecognitionRequest, error)
+	grpc.ServerStream
+}
+
+type sttServiceStreamingRecognizeServer struct {
+	grpc.ServerStream
+}
+
+func (x *sttServiceStreamingRecognizeServer) Send(m *StreamingRecognitionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sttServiceStreamingRecognizeServer) Recv() (*StreamingRecognitionRequest, error) {
+	m := new(StreamingRecognitionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SttService_ServiceDesc is the grpc.ServiceDesc for SttService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SttService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vosk.stt.v1.SttService",
+	HandlerType: (*SttServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamingRecognize",
+			Handler:       _SttService_StreamingRecognize_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "stt_service.proto",
+}
@@ -0,0 +1,85 @@
+// Package rtp reads the RTP packets of a media stream, RFC 3550.
+package rtp
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	version    = 2
+	headerSize = 12
+)
+
+var (
+	ErrShortPacket = errors.New("rtp: short packet")
+	ErrVersion     = errors.New("rtp: unsupported version")
+)
+
+// Packet is a parsed RTP packet, Payload refers to the parsed buffer.
+type Packet struct {
+	PayloadType    uint8
+	Marker         bool
+	SequenceNumber uint16
+	Timestamp      uint32
+	SSRC           uint32
+	Payload        []byte
+}
+
+// Parse reads the header of b and strips CSRCs, the extension and the padding from the payload.
+func Parse(b []byte) (Packet, error) {
+	var p Packet
+
+	if len(b) < headerSize {
+		return p, ErrShortPacket
+	}
+
+	if b[0]>>6 != version {
+		return p, ErrVersion
+	}
+
+	padding := b[0]&0x20 != 0
+	extension := b[0]&0x10 != 0
+	csrcCount := int(b[0] & 0x0f)
+
+	p.Marker = b[1]&0x80 != 0
+	p.PayloadType = b[1] & 0x7f
+	p.SequenceNumber = binary.BigEndian.Uint16(b[2:4])
+	p.Timestamp = binary.BigEndian.Uint32(b[4:8])
+	p.SSRC = binary.BigEndian.Uint32(b[8:12])
+
+	offset := headerSize + 4*csrcCount
+
+	if extension {
+		if len(b) < offset+4 {
+			return p, ErrShortPacket
+		}
+
+		offset += 4 + 4*int(binary.BigEndian.Uint16(b[offset+2:offset+4]))
+	}
+
+	end := len(b)
+
+	if padding {
+		end -= int(b[end-1])
+	}
+
+	if offset > end {
+		return p, ErrShortPacket
+	}
+
+	p.Payload = b[offset:end]
+
+	return p, nil
+}
+
+// Lost is the number of packets missing between the sequence numbers prev and next.
+// Reordered and duplicated packets count as none lost.
+func Lost(prev, next uint16) int {
+	gap := next - prev
+	if gap == 0 || gap > 1<<15 {
+		return 0
+	}
+
+	return int(gap) - 1
+}
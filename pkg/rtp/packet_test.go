@@ -0,0 +1,66 @@
+//go:build test && !integration
+
+package rtp_test
+
+import (
+	"testing"
+
+	"github.com/Arten331/bot-checker/pkg/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	packet := []byte{
+		0x80, 0x80 | 0, 0x01, 0x02, // v2, marker, PCMU, seq 258
+		0x00, 0x00, 0x00, 0xa0, // timestamp 160
+		0xde, 0xad, 0xbe, 0xef, // ssrc
+		0x7f, 0xff, 0x00,
+	}
+
+	p, err := rtp.Parse(packet)
+	require.NoError(t, err)
+	require.True(t, p.Marker)
+	require.EqualValues(t, 0, p.PayloadType)
+	require.EqualValues(t, 258, p.SequenceNumber)
+	require.EqualValues(t, 160, p.Timestamp)
+	require.EqualValues(t, 0xdeadbeef, p.SSRC)
+	require.Equal(t, []byte{0x7f, 0xff, 0x00}, p.Payload)
+}
+
+func TestParse_CSRCExtensionPadding(t *testing.T) {
+	packet := []byte{
+		0xb1, 0x0b, 0x00, 0x01, // v2, padding, extension, 1 csrc, payload type 11
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x02, // csrc
+		0xbe, 0xde, 0x00, 0x01, // extension of one word
+		0x01, 0x02, 0x03, 0x04,
+		0x11, 0x22, // payload
+		0x00, 0x00, 0x03, // padding
+	}
+
+	p, err := rtp.Parse(packet)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, p.PayloadType)
+	require.Equal(t, []byte{0x11, 0x22}, p.Payload)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := rtp.Parse([]byte{0x80, 0x00})
+	require.ErrorIs(t, err, rtp.ErrShortPacket)
+
+	_, err = rtp.Parse(make([]byte, 12))
+	require.ErrorIs(t, err, rtp.ErrVersion)
+
+	// extension header beyond the packet
+	_, err = rtp.Parse([]byte{0x90, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xbe})
+	require.ErrorIs(t, err, rtp.ErrShortPacket)
+}
+
+func TestLost(t *testing.T) {
+	require.Equal(t, 0, rtp.Lost(10, 11))
+	require.Equal(t, 2, rtp.Lost(10, 13))
+	require.Equal(t, 1, rtp.Lost(65535, 1))
+	require.Equal(t, 0, rtp.Lost(10, 10))
+	require.Equal(t, 0, rtp.Lost(10, 9))
+}